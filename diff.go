@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/converter"
+	"github.com/sl1pm4t/k2tf/pkg/drift"
+	"github.com/sl1pm4t/k2tf/pkg/file_io"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	flag "github.com/spf13/pflag"
+)
+
+// diffMain runs the diff command, which reports drift between Kubernetes YAML and the Terraform config
+// previously generated from it, and returns the exit code
+func diffMain(args []string) int {
+	var terraform, format string
+
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.BoolVarP(&debug, "debug", "d", false, "enable debug output")
+	fs.StringVarP(&input, "filepath", "f", "-", `file or directory that contains the YAML configuration to compare. Use "-" to read from stdin`)
+	fs.BoolVarP(&recursive, "recursive", "R", false, "read input directories recursively")
+	fs.StringVarP(&terraform, "terraform", "t", ".", `Terraform config file, or directory of .tf files, to compare the YAML with`)
+	fs.StringVarP(&output, "output", "o", "-", `file where the drift report will be written`)
+	fs.StringVar(&format, "format", "text", `format of the drift report: "text" or "json"`)
+	fs.BoolVarP(&overwriteExisting, "overwrite-existing", "x", false, "allow overwriting existing output file")
+	fs.BoolVarP(&includeUnsupported, "include-unsupported", "I", false, `include unsupported Attributes / Blocks in the generated TF config`)
+	fs.BoolVarP(&manifestFallback, "manifest-unsupported", "m", false, `render kinds not supported by the Terraform provider as kubernetes_manifest resources`)
+	fs.BoolVarP(&references, "references", "r", false, `replace names of other objects in the same conversion with Terraform references`)
+	fs.BoolVar(&stripDefaults, "strip-defaults", false, `omit attributes set to their Terraform provider or Kubernetes API default value`)
+	fs.BoolVar(&lastApplied, "last-applied", false, `convert the kubectl.kubernetes.io/last-applied-configuration annotation of each object, when present`)
+	fs.StringArrayVar(&metadataFilters, "metadata-filter", nil, `remove matching metadata, e.g. "annotation:example.com/*" (can be repeated)`)
+	fs.BoolVar(&defaultFilters, "default-metadata-filters", false, `remove metadata managed by kubectl, controllers and the API server`)
+	fs.BoolVar(&filterSelectors, "metadata-filter-selectors", false, `also remove labels removed by metadata filters from label selectors`)
+	fs.StringVar(&providerSchema, "provider-schema", "", `file containing the output of "terraform providers schema -json"`)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	setupLogOutput()
+
+	if format != "text" && format != "json" {
+		log.Fatal().Str("format", format).Msg(`unknown drift report format, must be "text" or "json"`)
+	}
+
+	if providerSchema != "" {
+		if err := tfkschema.LoadProviderSchema(providerSchema); err != nil {
+			log.Fatal().Err(err).Str("file", providerSchema).Msg("could not load provider schema")
+		}
+	}
+
+	existing, err := drift.ParseFiles(terraformFiles(terraform))
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not parse Terraform config")
+	}
+
+	in := file_io.ReadInput(input, file_io.InputOptions{Recursive: recursive})
+	log.Debug().Msgf("read %d objects from input", len(in.Objects))
+
+	filter := metadataFilter()
+
+	conv := converter.New(converter.Options{
+		MetadataFilter:      filter,
+		IncludeUnsupported:  includeUnsupported,
+		StripDefaults:       stripDefaults,
+		LastApplied:         lastApplied,
+		ManifestUnsupported: manifestFallback,
+		References:          references,
+	})
+	results, err := conv.Convert(in.Objects)
+	if err != nil {
+		log.Fatal().Err(err).Msg("error converting objects")
+	}
+
+	var generated []*drift.Resource
+	sources := map[string]string{}
+	for _, r := range results {
+		if r.Skipped {
+			continue
+		}
+
+		res, err := drift.Parse(r.HCL, r.Address()+".tf")
+		if err != nil {
+			log.Fatal().Err(err).Str("address", r.Address()).Msg("could not parse generated config")
+		}
+		generated = append(generated, res...)
+		sources[r.Address()] = in.Source(r.Input)
+	}
+
+	rep := drift.Compare(generated, existing, sources)
+
+	w, closer := file_io.SetupOutput(output, overwriteExisting)
+	defer closer()
+
+	if format == "json" {
+		err = rep.WriteJSON(w)
+	} else {
+		err = rep.WriteText(w)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not write drift report")
+	}
+
+	if rep.HasDrift() {
+		return exitDrift
+	}
+	return exitOK
+}
@@ -1,15 +1,28 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/hashicorp/hcl/hcl/printer"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/cluster"
+	"github.com/sl1pm4t/k2tf/pkg/converter"
 	"github.com/sl1pm4t/k2tf/pkg/file_io"
+	"github.com/sl1pm4t/k2tf/pkg/metafilter"
+	"github.com/sl1pm4t/k2tf/pkg/report"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
 	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/sl1pm4t/k2tf/pkg/verify"
 	flag "github.com/spf13/pflag"
-	"os"
-
-	"github.com/rs/zerolog/log"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // Build time variables
@@ -23,79 +36,577 @@ var (
 var (
 	debug              bool
 	input              string
+	recursive          bool
+	includeGlobs       []string
+	excludeGlobs       []string
+	useIgnoreFile      bool
 	output             string
+	outputLayout       string
+	outputFormat       string
 	includeUnsupported bool
+	stripDefaults      bool
+	lastApplied        bool
+	metadataFilters    []string
+	defaultFilters     bool
+	filterSelectors    bool
+	manifestFallback   bool
+	references         bool
+	forEach            bool
+	forEachMaxDiffs    int
+	parameterize       bool
+	parameterPaths     []string
+	parameterVariables bool
+	secretVariables    bool
+	secretTfvars       string
+	importBlocks       bool
+	importScript       string
 	noColor            bool
 	overwriteExisting  bool
+	mergeExisting      bool
 	tf12format         bool
 	printVersion       bool
+	providerSchema     string
+	requiredProviders  bool
+	providerVersion    string
+	providerBlock      bool
+	helmValues         []string
+	helmSet            []string
+	helmReleaseName    string
+	sourceComments     bool
+	verifyConversion   bool
+	reportFile         string
+	strict             bool
+	fromCluster        bool
+	kubeconfig         string
+	kubeContext        string
+	namespace          string
+	allNamespaces      bool
+	kinds              []string
+	labelSelector      string
+)
+
+// providersFile is the file in the output directory where the config written by --required-providers
+// and --provider-block goes
+const providersFile = "versions.tf"
+
+// secretTfvarsFile is the file next to the output file, or in the output directory, where the template
+// written by --secret-variables goes, unless --secret-tfvars is set
+const secretTfvarsFile = "secrets.tfvars"
+
+// Exit codes of the conversion. In --strict mode, when several apply, the lowest non-zero code is used.
+const (
+	exitOK                    = 0
+	exitError                 = 1
+	exitParseErrors           = 2
+	exitUnsupportedKinds      = 3
+	exitUnsupportedAttributes = 4
+	// exitDrift is returned by the diff command when the YAML and the Terraform config differ
+	exitDrift = 5
+	// exitDifferences is returned in --strict mode when --verify found differences
+	exitDifferences = 6
 )
 
 func init() {
-	// init command line flags
+	// init command line flags, parse errors are handled in main to return a documented exit code
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
 	flag.BoolVarP(&overwriteExisting, "overwrite-existing", "x", false, "allow overwriting existing output file(s)")
+	flag.BoolVar(&mergeExisting, "merge", false, "merge generated config into existing output file(s), keeping manual edits")
 	flag.BoolVarP(&debug, "debug", "d", false, "enable debug output")
 	flag.StringVarP(&input, "filepath", "f", "-", `file or directory that contains the YAML configuration to convert. Use "-" to read from stdin`)
+	flag.BoolVarP(&recursive, "recursive", "R", false, `read files in sub-directories of the input directory`)
+	flag.StringArrayVar(&includeGlobs, "include", nil, `glob pattern of files to read from the input directory (can be repeated, default "*.yaml" and "*.yml")`)
+	flag.StringArrayVar(&excludeGlobs, "exclude", nil, `glob pattern of files or directories to skip in the input directory (can be repeated)`)
+	flag.BoolVar(&useIgnoreFile, "ignore-file", false, `read additional exclude patterns from a .k2tfignore file in the input directory`)
+	flag.BoolVar(&fromCluster, "from-cluster", false, `read the objects to convert from a Kubernetes cluster, instead of files or stdin`)
+	flag.StringVar(&kubeconfig, "kubeconfig", "", `kubeconfig file used with --from-cluster (default: $KUBECONFIG or ~/.kube/config)`)
+	flag.StringVar(&kubeContext, "context", "", `kubeconfig context used with --from-cluster (default: the current context)`)
+	flag.StringVarP(&namespace, "namespace", "n", "", `namespace to read objects from with --from-cluster (default: the namespace of the kubeconfig context)`)
+	flag.BoolVarP(&allNamespaces, "all-namespaces", "A", false, `read objects from all namespaces with --from-cluster`)
+	flag.StringSliceVar(&kinds, "kinds", nil, `comma separated kinds to read with --from-cluster, e.g. "deployment,service,configmaps"`)
+	flag.StringVar(&labelSelector, "selector", "", `label selector of the objects to read with --from-cluster, e.g. "app=web"`)
 	flag.StringVarP(&output, "output", "o", "-", `file or directory where Terraform config will be written`)
+	flag.StringVarP(&outputLayout, "output-layout", "l", string(file_io.LayoutSingle), `how to split Terraform config across files in the output directory: "single", "object" (one file per object), "namespace" or "type" (one file per Terraform resource type)`)
+	flag.StringVar(&outputFormat, "output-format", "hcl", `syntax of the generated Terraform config: "hcl", or "json" for Terraform JSON syntax (.tf.json)`)
 	flag.BoolVarP(&includeUnsupported, "include-unsupported", "I", false, `set to true to include unsupported Attributes / Blocks in the generated TF config`)
+	flag.BoolVar(&stripDefaults, "strip-defaults", false, `omit attributes set to their Terraform provider or Kubernetes API default value, e.g. dns_policy = "ClusterFirst"`)
+	flag.BoolVar(&lastApplied, "last-applied", false, `convert the configuration stored in the kubectl.kubernetes.io/last-applied-configuration annotation of each object instead of the object itself, when present`)
+	flag.StringArrayVar(&metadataFilters, "metadata-filter", nil, `remove matching metadata, e.g. "annotation:example.com/*", "label:pod-template-hash" or "field:managedFields". Prefix with "!" to keep metadata removed by an earlier rule (can be repeated)`)
+	flag.BoolVar(&defaultFilters, "default-metadata-filters", false, `remove metadata managed by kubectl, controllers and the API server, e.g. managedFields and the last-applied-configuration annotation`)
+	flag.BoolVar(&filterSelectors, "metadata-filter-selectors", false, `also remove labels removed by metadata filters from label selectors. By default, labels used by selectors are kept in templates`)
+	flag.BoolVarP(&manifestFallback, "manifest-unsupported", "m", false, `render kinds not supported by the Terraform provider (e.g. Custom Resources) as kubernetes_manifest resources`)
+	flag.BoolVarP(&references, "references", "r", false, `replace names of other objects in the same conversion (e.g. namespaces, config maps, secrets) with Terraform references`)
+	flag.BoolVar(&forEach, "for-each", false, `collapse near-identical resources of the same type into a single resource with for_each over a generated local map`)
+	flag.IntVar(&forEachMaxDiffs, "for-each-max-diffs", converter.DefaultForEachMaxDiffs, `maximum number of distinct values that may differ between resources collapsed by --for-each`)
+	flag.BoolVar(&parameterize, "parameterize", false, `lift namespaces, images, replicas, resource limits and labels that are repeated across resources into local values`)
+	flag.StringArrayVar(&parameterPaths, "parameter", nil, `attribute path whose values are lifted into local values, e.g. "spec.template.spec.container.image". "*" matches one attribute or block name, "**" any number (can be repeated)`)
+	flag.BoolVar(&parameterVariables, "parameter-variables", false, `lift values into variables with a default value instead of local values, with --parameterize or --parameter`)
+	flag.BoolVar(&secretVariables, "secret-variables", false, `replace Secret values with references to sensitive Terraform variables, instead of writing them to the generated config`)
+	flag.StringVar(&secretTfvars, "secret-tfvars", "", `file where a template for the values of the variables generated by --secret-variables will be written. Use "-" to write to stdout (default: secrets.tfvars next to the output file, or in the output directory)`)
+	flag.BoolVarP(&importBlocks, "import-blocks", "i", false, `emit a Terraform 1.5+ import block for each generated resource`)
+	flag.StringVar(&importScript, "import-script", "", `file where a shell script of "terraform import" commands for each generated resource will be written. Use "-" to write to stdout`)
 	flag.BoolVarP(&tf12format, "tf12format", "F", false, `Use Terraform 0.12 formatter`)
+	flag.StringVar(&providerSchema, "provider-schema", "", `file containing the output of "terraform providers schema -json", used instead of the compiled-in Kubernetes provider schema`)
+	flag.BoolVar(&requiredProviders, "required-providers", false, `write a terraform block requiring the Kubernetes provider, at the version whose schema is used for the conversion`)
+	flag.StringVar(&providerVersion, "provider-version", "", `version constraint of the Kubernetes provider written by --required-providers, e.g. "~> 2.23"; required with --provider-schema (default: derived from the compiled-in provider schema)`)
+	flag.BoolVar(&providerBlock, "provider-block", false, `write a provider "kubernetes" block, configured with the --kubeconfig file (default "~/.kube/config") and --context`)
+	flag.StringArrayVar(&helmValues, "values", nil, `values file used when rendering a Helm chart input (can be repeated)`)
+	flag.StringArrayVar(&helmSet, "set", nil, `set values when rendering a Helm chart input, e.g. --set image.tag=1.2.3 (can be repeated)`)
+	flag.StringVar(&helmReleaseName, "release-name", "", `release name used when rendering a Helm chart input`)
+	flag.BoolVar(&sourceComments, "source-comments", false, `write the file or Helm chart template each object was read from as a comment above the generated resource. Enabled by default for Helm chart input`)
+	flag.BoolVar(&verifyConversion, "verify", false, `decode the generated Terraform config back to Kubernetes objects, and report every field that was dropped, renamed or changed`)
+	flag.StringVar(&reportFile, "report", "", `file where a JSON report of converted and skipped objects, excluded fields and parse errors will be written. Use "-" to write to stdout`)
+	flag.BoolVar(&strict, "strict", false, `exit with a non-zero code when input could not be parsed (2), kinds were skipped (3), attributes were excluded from the generated config (4), or --verify found differences (6)`)
 	flag.BoolVarP(&printVersion, "version", "v", false, `Print k2tf version`)
+}
 
-	flag.Parse()
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "tf2k" {
+		os.Exit(tf2kMain(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(diffMain(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "module" {
+		os.Exit(moduleMain(os.Args[2:]))
+	}
 
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(exitOK)
+		}
+		os.Exit(exitError)
+	}
 	setupLogOutput()
-}
 
-func main() {
 	if printVersion {
 		fmt.Printf("k2tf version: %s\n", version)
 		os.Exit(0)
 	}
 
+	os.Exit(run())
+}
+
+// run converts the input, and returns the exit code
+func run() int {
 	log.Debug().
 		Str("version", version).
 		Str("commit", commit).
 		Str("builddate", date).
 		Msg("starting k2tf")
 
-	objs := file_io.ReadInput(input)
+	if providerSchema != "" {
+		if err := tfkschema.LoadProviderSchema(providerSchema); err != nil {
+			log.Fatal().Err(err).Str("file", providerSchema).Msg("could not load provider schema")
+		}
+		log.Debug().Str("file", providerSchema).Msg("loaded provider schema")
 
-	log.Debug().Msgf("read %d objects from input", len(objs))
+		if requiredProviders && providerVersion == "" {
+			log.Fatal().Msg("--provider-version is required with --provider-schema, the loaded schema doesn't include the provider version")
+		}
+	}
 
-	w, closer := file_io.SetupOutput(output, overwriteExisting)
-	defer closer()
+	var in *file_io.Input
+	if fromCluster {
+		if flag.CommandLine.Changed("filepath") {
+			log.Fatal().Msg("--from-cluster cannot be combined with --filepath")
+		}
+		in = &file_io.Input{Objects: readCluster()}
 
-	for i, obj := range objs {
-		if tfkschema.IsKubernetesKindSupported(obj) {
-			f := hclwrite.NewEmptyFile()
-			_, err := WriteObject(obj, f.Body())
-			if err != nil {
-				log.Error().Int("obj#", i).Err(err).Msg("error writing object")
-			}
+	} else {
+		in = file_io.ReadInput(input, file_io.InputOptions{
+			Recursive:     recursive,
+			Include:       includeGlobs,
+			Exclude:       excludeGlobs,
+			UseIgnoreFile: useIgnoreFile,
+			Helm: file_io.HelmOptions{
+				ValuesFiles: helmValues,
+				Set:         helmSet,
+				ReleaseName: helmReleaseName,
+			},
+		})
+	}
 
-			formatted := formatObject(f.Bytes())
+	log.Debug().Msgf("read %d objects from input", len(in.Objects))
 
-			fmt.Fprint(w, string(formatted))
-			fmt.Fprintln(w)
+	var importW io.Writer
+	if importScript != "" {
+		var closer file_io.CloseFunc
+		importW, closer = file_io.SetupOutput(importScript, overwriteExisting)
+		defer closer()
+
+		if err := converter.WriteImportScriptHeader(importW); err != nil {
+			log.Fatal().Err(err).Msg("could not write import script")
+		}
+	}
+
+	layout, err := file_io.ParseLayout(outputLayout)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+
+	jsonOutput := outputFormat == "json"
+	if !jsonOutput && outputFormat != "hcl" {
+		log.Fatal().Str("format", outputFormat).Msg(`unknown output format, must be "hcl" or "json"`)
+	}
+	if jsonOutput && mergeExisting {
+		log.Fatal().Msg("--merge is not supported with JSON output")
+	}
+
+	// provider requirements and configuration are written to the output file, or to a versions.tf file
+	// in the output directory
+	var writerFor func(runtime.Object) io.Writer
+	var providersWriter func() io.Writer
+	switch {
+	case layout == file_io.LayoutSingle && mergeExisting:
+		w, closer := file_io.SetupMergeOutput(output)
+		defer closer()
+		writerFor = func(runtime.Object) io.Writer { return w }
+		providersWriter = func() io.Writer { return w }
+
+	case layout == file_io.LayoutSingle:
+		w, closer := file_io.SetupOutput(output, overwriteExisting)
+		defer closer()
+		writerFor = func(runtime.Object) io.Writer { return w }
+		providersWriter = func() io.Writer { return w }
+
+	case mergeExisting:
+		d, closer := file_io.SetupDirectoryMergeOutput(output, layout)
+		defer closer()
+		writerFor = d.Writer
+		providersWriter = func() io.Writer { return d.File(providersFile) }
+
+	default:
+		d, closer := file_io.SetupDirectoryOutput(output, layout, overwriteExisting)
+		defer closer()
+		name := providersFile
+		if jsonOutput {
+			d.Extension = ".tf.json"
+			name += ".json"
+		}
+		writerFor = d.Writer
+		providersWriter = func() io.Writer { return d.File(name) }
+	}
+
+	filter := metadataFilter()
+
+	opts := converter.Options{
+		MetadataFilter:      filter,
+		IncludeUnsupported:  includeUnsupported,
+		StripDefaults:       stripDefaults,
+		LastApplied:         lastApplied,
+		ManifestUnsupported: manifestFallback,
+		References:          references,
+		ForEach:             forEach,
+		ForEachMaxDiffs:     forEachMaxDiffs,
+		Parameterize:        parameterize,
+		ParameterPaths:      parameterPaths,
+		ParameterVariables:  parameterVariables,
+		SecretVariables:     secretVariables,
+		ImportBlocks:        importBlocks,
+		TF12Format:          tf12format,
+	}
+	if mergeExisting {
+		// merged files are parsed as HCL2, which the output of the HCL1 printer is not guaranteed to be
+		opts.TF12Format = true
+	}
+	if sourceComments || (!flag.CommandLine.Changed("source-comments") && file_io.IsHelmChart(input)) {
+		opts.Source = in.Source
+	}
+
+	conv := converter.New(opts)
+
+	results, convErr := conv.Convert(in.Objects)
+	if convErr != nil {
+		log.Error().Err(convErr).Msg("error converting objects")
+	}
+
+	rep := report.New()
+	var secretVars []converter.SecretVariable
+
+	// JSON documents can't be concatenated, so the config for each output file is collected,
+	// and written once all objects are converted
+	jsonConfig := map[io.Writer]*tfconfig.Body{}
+	var jsonWriters []io.Writer
+
+	if requiredProviders || providerBlock {
+		w := providersWriter()
+		config := providersConfig()
+		if jsonOutput {
+			jsonConfig[w] = config
+			jsonWriters = append(jsonWriters, w)
 		} else {
-			log.Warn().Str("kind", obj.GetObjectKind().GroupVersionKind().Kind).Msg("skipping API object, kind not supported by Terraform provider.")
+			fmt.Fprintln(w, string(hclwrite.Format(config.HCL())))
+		}
+	}
+
+	var batch *verify.Batch
+	if verifyConversion {
+		batch = verify.NewBatch(results)
+	}
+
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+
+		if r.Skipped {
+			log.Warn().Str("kind", r.Object.GetObjectKind().GroupVersionKind().Kind).Msg("skipping API object, kind not supported by Terraform provider.")
+			rep.AddResult(r, in.Source(r.Input), nil)
+			continue
+		}
+
+		w := writerFor(r.Object)
+		switch {
+		case r.Config == nil:
+			// collapsed into the for_each resource of another result
+			log.Debug().Str("address", r.Address()).Msg("resource collapsed with for_each")
+
+		case jsonOutput:
+			body, ok := jsonConfig[w]
+			if !ok {
+				body = tfconfig.NewBody()
+				jsonConfig[w] = body
+				jsonWriters = append(jsonWriters, w)
+			}
+			for _, b := range r.Config.Blocks() {
+				body.AppendBlock(b)
+			}
+
+		default:
+			fmt.Fprint(w, string(r.HCL))
+			fmt.Fprintln(w)
+		}
+
+		if importW != nil {
+			if err := r.WriteImportCommand(importW); err != nil {
+				log.Error().Err(err).Msg("error writing import command")
+			}
+		}
+
+		secretVars = append(secretVars, r.SecretVariables...)
+
+		var diffs []verify.Difference
+		if verifyConversion {
+			diffs = verifyResult(batch, r)
+		}
+		rep.AddResult(r, in.Source(r.Input), diffs)
+	}
+
+	for _, w := range jsonWriters {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(jsonConfig[w]); err != nil {
+			log.Fatal().Err(err).Msg("could not write Terraform JSON config")
+		}
+	}
+
+	if len(secretVars) > 0 {
+		writeSecretTfvars(secretTfvarsPath(layout), secretVars)
+	}
+
+	rep.AddParseErrors(in.ParseErrors)
+
+	if reportFile != "" {
+		w, closer := file_io.SetupOutput(reportFile, overwriteExisting)
+		defer closer()
+
+		if err := rep.Write(w); err != nil {
+			log.Fatal().Err(err).Msg("could not write report")
 		}
+		log.Debug().Str("file", reportFile).Msg("wrote conversion report")
 	}
+
+	if !strict {
+		return exitOK
+	}
+	return strictExitCode(rep.Summary, convErr)
 }
 
-func formatObject(in []byte) []byte {
-	var result []byte
-	var err error
+// readCluster reads the objects selected by the --from-cluster flags
+func readCluster() []runtime.Object {
+	if len(kinds) == 0 {
+		log.Fatal().Msg("--from-cluster requires --kinds")
+	}
 
-	if tf12format {
-		result = hclwrite.Format(in)
-	} else {
-		result, err = printer.Format(in)
+	config, contextNamespace, err := cluster.LoadConfig(kubeconfig, kubeContext)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not load kubeconfig")
+	}
+
+	r, err := cluster.NewReaderForConfig(config)
+	if err != nil {
+		log.Fatal().Err(err).Str("host", config.Host).Msg("could not connect to cluster")
+	}
+
+	opts := cluster.Options{
+		Kinds:         kinds,
+		Namespace:     namespace,
+		AllNamespaces: allNamespaces,
+		LabelSelector: labelSelector,
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = contextNamespace
+	}
+
+	objs, err := r.Read(context.Background(), opts)
+	if err != nil {
+		log.Fatal().Err(err).Str("host", config.Host).Msg("could not read objects from cluster")
+	}
+
+	return objs
+}
+
+// strictExitCode returns the exit code of a --strict conversion, logging the reason of a failure
+func strictExitCode(s report.Summary, convErr error) int {
+	switch {
+	case convErr != nil:
+		log.Error().Msg("strict: objects could not be converted")
+		return exitError
+	case s.ParseErrors > 0:
+		log.Error().Msgf("strict: %d inputs could not be parsed", s.ParseErrors)
+		return exitParseErrors
+	case s.Skipped > 0:
+		log.Error().Msgf("strict: %d objects were skipped, kind not supported by Terraform provider", s.Skipped)
+		return exitUnsupportedKinds
+	case s.SkippedFields > 0:
+		log.Error().Msgf("strict: %d attributes were excluded, not found in Terraform schema", s.SkippedFields)
+		return exitUnsupportedAttributes
+	case s.Differences > 0:
+		log.Error().Msgf("strict: %d differences were found verifying the conversion", s.Differences)
+		return exitDifferences
+	}
+	return exitOK
+}
+
+// verifyResult logs and returns the differences between a converted object, and the object decoded from the
+// config generated for the batch. Differences explained by fields that are not supported by the Terraform provider are logged
+// at info level.
+func verifyResult(batch *verify.Batch, r *converter.Result) []verify.Difference {
+	diffs, err := batch.Verify(r)
+	if err != nil {
+		log.Error().Err(err).Str("resource", r.Address()).Msg("could not verify conversion")
+		return nil
+	}
+
+	for _, d := range diffs {
+		e := log.Warn()
+		if d.Unsupported != nil {
+			e = log.Info()
+		}
+		e.Str("resource", r.Address()).Msg(d.String())
+	}
+
+	if len(diffs) == 0 {
+		log.Debug().Str("resource", r.Address()).Msg("verified conversion, no differences")
+	}
+
+	return diffs
+}
+
+// providersConfig returns the terraform and provider blocks requested with --required-providers and
+// --provider-block
+func providersConfig() *tfconfig.Body {
+	body := tfconfig.NewBody()
+
+	if requiredProviders {
+		converter.WriteRequiredProviders(providerVersionConstraint(), body)
+	}
+
+	if providerBlock {
+		configPath := kubeconfig
+		if configPath == "" {
+			configPath = "~/.kube/config"
+		}
+		converter.WriteProviderConfig(configPath, kubeContext, body)
+	}
+
+	return body
+}
+
+// providerVersionConstraint returns the --provider-version constraint, or the constraint derived from the
+// compiled-in provider schema
+func providerVersionConstraint() string {
+	if providerVersion != "" {
+		return providerVersion
+	}
+
+	constraint := tfkschema.ProviderVersionConstraint()
+	if constraint == "" {
+		log.Warn().Msg("version of the Kubernetes provider schema is not known, set one with --provider-version")
+	}
+	return constraint
+}
+
+// metadataFilter returns the metadata filter configured by --metadata-filter, --default-metadata-filters and
+// --metadata-filter-selectors
+// secretTfvarsPath returns the --secret-tfvars file, or the default file next to the output file, or in the
+// output directory
+func secretTfvarsPath(layout file_io.Layout) string {
+	switch {
+	case secretTfvars != "":
+		return secretTfvars
+	case output == "" || output == "-":
+		return secretTfvarsFile
+	case layout == file_io.LayoutSingle:
+		return filepath.Join(filepath.Dir(output), secretTfvarsFile)
+	default:
+		return filepath.Join(output, secretTfvarsFile)
+	}
+}
+
+// writeSecretTfvars writes the template for the values of the secret variables to name. With --merge, the
+// variables that are not set in the existing file are appended to it, keeping the values filled in.
+func writeSecretTfvars(name string, vars []converter.SecretVariable) {
+	var w io.Writer
+	if mergeExisting && name != "-" {
+		existing, err := os.ReadFile(name)
+		if err != nil && !os.IsNotExist(err) {
+			log.Fatal().Err(err).Msg("")
+		}
+		f, diags := hclwrite.ParseConfig(existing, name, hcl.InitialPos)
+		if diags.HasErrors() {
+			log.Fatal().Err(diags).Str("file", name).Msg("could not parse secret variables template")
+		}
+
+		var missing []converter.SecretVariable
+		for _, v := range vars {
+			if f.Body().GetAttribute(v.Name) == nil {
+				missing = append(missing, v)
+			}
+		}
+		if len(missing) == 0 {
+			log.Debug().Str("file", name).Msg("all secret variables are set in the existing template")
+			return
+		}
+		vars = missing
+
+		out, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
 		if err != nil {
-			log.Error().Err(err).Msg("could not format object")
-			return in
+			log.Fatal().Err(err).Msg("")
 		}
+		defer out.Close()
+		if len(bytes.TrimSpace(existing)) > 0 {
+			fmt.Fprintln(out)
+		}
+		w = out
+	} else {
+		var closer file_io.CloseFunc
+		w, closer = file_io.SetupOutput(name, overwriteExisting)
+		defer closer()
 	}
 
-	return result
+	if err := converter.WriteSecretVariablesTemplate(vars, w); err != nil {
+		log.Fatal().Err(err).Msg("could not write secret variables template")
+	}
+	log.Info().Str("file", name).Msgf("wrote template for %d secret variables", len(vars))
+}
+
+func metadataFilter() *metafilter.Filter {
+	filter, err := metafilter.Parse(metadataFilters, defaultFilters)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	filter.Selectors = filterSelectors
+	return filter
 }
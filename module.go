@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/converter"
+	"github.com/sl1pm4t/k2tf/pkg/file_io"
+	"github.com/sl1pm4t/k2tf/pkg/module"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	flag "github.com/spf13/pflag"
+)
+
+// moduleMain runs the module command, which converts Kubernetes YAML to a reusable Terraform module,
+// and returns the exit code
+func moduleMain(args []string) int {
+	fs := flag.NewFlagSet("module", flag.ContinueOnError)
+	fs.BoolVarP(&debug, "debug", "d", false, "enable debug output")
+	fs.StringVarP(&input, "filepath", "f", "-", `file or directory that contains the YAML configuration to convert. Use "-" to read from stdin`)
+	fs.BoolVarP(&recursive, "recursive", "R", false, "read input directories recursively")
+	fs.StringVarP(&output, "output", "o", "", `directory where the module will be written`)
+	fs.BoolVarP(&overwriteExisting, "overwrite-existing", "x", false, "allow overwriting existing module files")
+	fs.BoolVarP(&includeUnsupported, "include-unsupported", "I", false, `include unsupported Attributes / Blocks in the generated TF config`)
+	fs.BoolVarP(&manifestFallback, "manifest-unsupported", "m", false, `render kinds not supported by the Terraform provider as kubernetes_manifest resources`)
+	fs.BoolVarP(&references, "references", "r", false, `replace names of other objects in the same conversion with Terraform references`)
+	fs.BoolVar(&stripDefaults, "strip-defaults", false, `omit attributes set to their Terraform provider or Kubernetes API default value`)
+	fs.BoolVar(&lastApplied, "last-applied", false, `convert the kubectl.kubernetes.io/last-applied-configuration annotation of each object, when present`)
+	fs.StringArrayVar(&metadataFilters, "metadata-filter", nil, `remove matching metadata, e.g. "annotation:example.com/*" (can be repeated)`)
+	fs.BoolVar(&defaultFilters, "default-metadata-filters", false, `remove metadata managed by kubectl, controllers and the API server`)
+	fs.BoolVar(&filterSelectors, "metadata-filter-selectors", false, `also remove labels removed by metadata filters from label selectors`)
+	fs.StringArrayVar(&parameterPaths, "parameter", nil, `additional attribute path whose values are lifted into input variables, e.g. "spec.template.spec.container.port.container_port" (can be repeated)`)
+	fs.StringVar(&providerSchema, "provider-schema", "", `file containing the output of "terraform providers schema -json"`)
+	fs.StringVar(&providerVersion, "provider-version", "", `version constraint of the Kubernetes provider written to versions.tf, e.g. "~> 2.23"; required with --provider-schema (default: derived from the compiled-in provider schema)`)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	setupLogOutput()
+
+	if output == "" || output == "-" {
+		log.Fatal().Msg("the module command requires an output directory, set with --output")
+	}
+
+	if providerSchema != "" {
+		if err := tfkschema.LoadProviderSchema(providerSchema); err != nil {
+			log.Fatal().Err(err).Str("file", providerSchema).Msg("could not load provider schema")
+		}
+		if providerVersion == "" {
+			log.Fatal().Msg("--provider-version is required with --provider-schema, the loaded schema doesn't include the provider version")
+		}
+	}
+
+	in := file_io.ReadInput(input, file_io.InputOptions{Recursive: recursive})
+	log.Debug().Msgf("read %d objects from input", len(in.Objects))
+
+	filter := metadataFilter()
+
+	conv := converter.New(converter.Options{
+		MetadataFilter:      filter,
+		IncludeUnsupported:  includeUnsupported,
+		StripDefaults:       stripDefaults,
+		LastApplied:         lastApplied,
+		ManifestUnsupported: manifestFallback,
+		References:          references,
+		// namespaces, images, replicas, labels etc. are inputs of the module
+		ParameterPaths:     append(append([]string{}, converter.DefaultParameterPaths...), parameterPaths...),
+		ParameterVariables: true,
+	})
+	results, err := conv.Convert(in.Objects)
+	if err != nil {
+		log.Fatal().Err(err).Msg("error converting objects")
+	}
+
+	for _, r := range results {
+		for _, warning := range r.Warnings {
+			log.Warn().Str("address", r.Address()).Msg(warning)
+		}
+	}
+
+	m := module.New(results, providerVersionConstraint())
+
+	d, closer := file_io.SetupDirectoryOutput(output, file_io.LayoutSingle, overwriteExisting)
+	defer closer()
+
+	for _, name := range module.Files {
+		if _, err := d.File(name).Write(m.HCL(name)); err != nil {
+			log.Fatal().Err(err).Str("file", name).Msg("could not write module")
+		}
+	}
+
+	return exitOK
+}
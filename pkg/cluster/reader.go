@@ -0,0 +1,278 @@
+// Package cluster reads Kubernetes API Objects from a live cluster, as input for the converter.
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// serverFields are populated by the API server, and removed from objects read from the cluster
+var serverFields = [][]string{
+	{"status"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionGracePeriodSeconds"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "generation"},
+	{"metadata", "managedFields"},
+	{"metadata", "ownerReferences"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "selfLink"},
+	{"metadata", "uid"},
+}
+
+// kindServerFields removes the fields of objects of a kind that are allocated by the API server or its controllers,
+// e.g. the cluster IP of a Service, from objects read from the cluster
+var kindServerFields = map[schema.GroupKind]func(obj map[string]interface{}){
+	{Kind: "Service"}:               removeServiceFields,
+	{Kind: "PersistentVolumeClaim"}: removePersistentVolumeClaimFields,
+	{Group: "batch", Kind: "Job"}:   removeJobFields,
+}
+
+// jobLabels are added to the selector and pod template of a Job by the API server
+var jobLabels = []string{
+	"controller-uid",
+	"job-name",
+	"batch.kubernetes.io/controller-uid",
+	"batch.kubernetes.io/job-name",
+}
+
+// Options selects the objects read from a cluster
+type Options struct {
+	// Kinds to read, as accepted by kubectl get, e.g. "deployment", "Service", "deploy" or "ingresses.networking.k8s.io"
+	Kinds []string
+	// Namespace of namespaced objects. Ignored when AllNamespaces is set.
+	Namespace string
+	// AllNamespaces reads namespaced objects from all namespaces
+	AllNamespaces bool
+	// LabelSelector restricts the objects read, e.g. "app=web,tier!=cache"
+	LabelSelector string
+}
+
+// Reader reads Kubernetes API Objects from a cluster
+type Reader struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+}
+
+// NewReader returns a Reader that lists objects with client, resolving kinds with the API resources
+// served by the discovery client.
+func NewReader(client dynamic.Interface, disco discovery.DiscoveryInterface) (*Reader, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(disco)
+	if err != nil {
+		return nil, fmt.Errorf("could not discover API resources: %w", err)
+	}
+
+	return &Reader{
+		client: client,
+		mapper: restmapper.NewShortcutExpander(restmapper.NewDiscoveryRESTMapper(groupResources), disco),
+	}, nil
+}
+
+// NewReaderForConfig returns a Reader for the cluster described by config
+func NewReaderForConfig(config *rest.Config) (*Reader, error) {
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	disco, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReader(client, disco)
+}
+
+// LoadConfig loads the client config of the given kubeconfig context. The default kubeconfig loading rules
+// (the KUBECONFIG environment variable, then ~/.kube/config) are used when kubeconfig is empty, and the current
+// context is used when context is empty.
+// The default namespace of the context is returned too.
+func LoadConfig(kubeconfig, context string) (*rest.Config, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+
+	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: context})
+
+	config, err := cc.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	namespace, _, err := cc.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return config, namespace, nil
+}
+
+// Read lists the objects selected by opts, in the order of opts.Kinds.
+// Fields populated by the API server (e.g. status, managedFields) are removed, and objects of kinds known to
+// the client-go scheme are converted to their typed API structs. Other objects (e.g. Custom Resources) are returned
+// as *unstructured.Unstructured.
+func (r *Reader) Read(ctx context.Context, opts Options) ([]runtime.Object, error) {
+	if len(opts.Kinds) == 0 {
+		return nil, fmt.Errorf("no kinds to read")
+	}
+
+	// resolve all kinds first, so that typos fail fast
+	var mappings []*meta.RESTMapping
+	seen := map[schema.GroupVersionResource]bool{}
+	for _, kind := range opts.Kinds {
+		mapping, err := r.resolve(kind)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[mapping.Resource] {
+			seen[mapping.Resource] = true
+			mappings = append(mappings, mapping)
+		}
+	}
+
+	var result error
+	var objs []runtime.Object
+	for _, mapping := range mappings {
+		read, err := r.list(ctx, mapping, opts)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("could not list %s: %w", mapping.Resource.String(), err))
+			continue
+		}
+		objs = append(objs, read...)
+	}
+
+	return objs, result
+}
+
+// resolve returns the REST mapping of a kind, as accepted by kubectl get
+func (r *Reader) resolve(kind string) (*meta.RESTMapping, error) {
+	gvr, err := r.mapper.ResourceFor(schema.ParseGroupResource(strings.ToLower(kind)).WithVersion(""))
+	if err != nil {
+		return nil, fmt.Errorf("unknown kind %q: %w", kind, err)
+	}
+
+	gvk, err := r.mapper.KindFor(gvr)
+	if err != nil {
+		return nil, fmt.Errorf("unknown kind %q: %w", kind, err)
+	}
+
+	return r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+func (r *Reader) list(ctx context.Context, mapping *meta.RESTMapping, opts Options) ([]runtime.Object, error) {
+	var ri dynamic.ResourceInterface = r.client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && !opts.AllNamespaces {
+		ri = r.client.Resource(mapping.Resource).Namespace(opts.Namespace)
+	}
+
+	list, err := ri.List(ctx, metav1.ListOptions{LabelSelector: opts.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().
+		Str("resource", mapping.Resource.String()).
+		Str("namespace", opts.Namespace).
+		Msgf("read %d objects from cluster", len(list.Items))
+
+	var objs []runtime.Object
+	for i := range list.Items {
+		u := &list.Items[i]
+		u.SetGroupVersionKind(mapping.GroupVersionKind)
+		for _, path := range serverFields {
+			unstructured.RemoveNestedField(u.Object, path...)
+		}
+		if remove, ok := kindServerFields[mapping.GroupVersionKind.GroupKind()]; ok {
+			remove(u.Object)
+		}
+
+		obj, err := toTyped(u)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode %s %s: %w", mapping.GroupVersionKind.Kind, u.GetName(), err)
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// toTyped converts u to its typed API struct, if the kind is known to the client-go scheme
+func toTyped(u *unstructured.Unstructured) (runtime.Object, error) {
+	if !scheme.Scheme.Recognizes(u.GroupVersionKind()) {
+		return u, nil
+	}
+
+	content, err := u.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return k8sparser.ParseJSON(content)
+}
+
+// removeServiceFields removes the allocated cluster IPs, node ports and health check node port of a Service. The
+// cluster IP of a headless Service ("None") is set by users, and kept. IP family settings are removed when they
+// hold the single stack default.
+func removeServiceFields(obj map[string]interface{}) {
+	if ip, _, _ := unstructured.NestedString(obj, "spec", "clusterIP"); ip != "None" {
+		unstructured.RemoveNestedField(obj, "spec", "clusterIP")
+		unstructured.RemoveNestedField(obj, "spec", "clusterIPs")
+	}
+	unstructured.RemoveNestedField(obj, "spec", "healthCheckNodePort")
+
+	if ports, ok, _ := unstructured.NestedSlice(obj, "spec", "ports"); ok {
+		for _, p := range ports {
+			if port, ok := p.(map[string]interface{}); ok {
+				delete(port, "nodePort")
+			}
+		}
+		_ = unstructured.SetNestedSlice(obj, ports, "spec", "ports")
+	}
+
+	if policy, _, _ := unstructured.NestedString(obj, "spec", "ipFamilyPolicy"); policy == "SingleStack" {
+		unstructured.RemoveNestedField(obj, "spec", "ipFamilyPolicy")
+		unstructured.RemoveNestedField(obj, "spec", "ipFamilies")
+	}
+}
+
+// removePersistentVolumeClaimFields removes the name of the volume a PersistentVolumeClaim was bound to
+func removePersistentVolumeClaimFields(obj map[string]interface{}) {
+	unstructured.RemoveNestedField(obj, "spec", "volumeName")
+}
+
+// removeJobFields removes the selector generated for a Job, unless it was set manually, and the labels the
+// generated selector matches from the pod template
+func removeJobFields(obj map[string]interface{}) {
+	if manual, _, _ := unstructured.NestedBool(obj, "spec", "manualSelector"); manual {
+		return
+	}
+	unstructured.RemoveNestedField(obj, "spec", "selector")
+
+	labels, _, _ := unstructured.NestedStringMap(obj, "spec", "template", "metadata", "labels")
+	if labels == nil {
+		return
+	}
+	for _, l := range jobLabels {
+		delete(labels, l)
+	}
+	if len(labels) == 0 {
+		unstructured.RemoveNestedField(obj, "spec", "template", "metadata", "labels")
+		return
+	}
+	_ = unstructured.SetNestedStringMap(obj, labels, "spec", "template", "metadata", "labels")
+}
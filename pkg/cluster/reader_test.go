@@ -0,0 +1,302 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func newObject(apiVersion, kind, namespace, name string, labels map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"name":              name,
+		"uid":               "0b6b9c2a-3f7e-4c43-8a43-1a2b3c4d5e6f",
+		"resourceVersion":   "1234",
+		"creationTimestamp": "2024-01-01T00:00:00Z",
+		"managedFields":     []interface{}{map[string]interface{}{"manager": "kubectl"}},
+	}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	if labels != nil {
+		metadata["labels"] = labels
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   metadata,
+		"status":     map[string]interface{}{"observedGeneration": int64(1)},
+	}}
+}
+
+// newTestReader returns a Reader for a fake cluster, holding objs in addition to the default test objects
+func newTestReader(t *testing.T, objs ...runtime.Object) *Reader {
+	disco := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	disco.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "services", SingularName: "service", Namespaced: true, Kind: "Service", ShortNames: []string{"svc"}, Verbs: []string{"list"}},
+				{Name: "namespaces", SingularName: "namespace", Namespaced: false, Kind: "Namespace", ShortNames: []string{"ns"}, Verbs: []string{"list"}},
+				{Name: "persistentvolumeclaims", SingularName: "persistentvolumeclaim", Namespaced: true, Kind: "PersistentVolumeClaim", ShortNames: []string{"pvc"}, Verbs: []string{"list"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", SingularName: "deployment", Namespaced: true, Kind: "Deployment", ShortNames: []string{"deploy"}, Verbs: []string{"list"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", SingularName: "widget", Namespaced: true, Kind: "Widget", Verbs: []string{"list"}},
+			},
+		},
+	}
+
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Version: "v1", Resource: "services"}:                      "ServiceList",
+			{Version: "v1", Resource: "namespaces"}:                    "NamespaceList",
+			{Version: "v1", Resource: "persistentvolumeclaims"}:        "PersistentVolumeClaimList",
+			{Group: "apps", Version: "v1", Resource: "deployments"}:    "DeploymentList",
+			{Group: "example.com", Version: "v1", Resource: "widgets"}: "WidgetList",
+		},
+		append([]runtime.Object{
+			newObject("v1", "Namespace", "", "web", nil),
+			newObject("v1", "Service", "web", "frontend", map[string]interface{}{"app": "frontend"}),
+			newObject("v1", "Service", "web", "backend", map[string]interface{}{"app": "backend"}),
+			newObject("v1", "Service", "other", "frontend", map[string]interface{}{"app": "frontend"}),
+			newObject("apps/v1", "Deployment", "web", "frontend", map[string]interface{}{"app": "frontend"}),
+			newObject("example.com/v1", "Widget", "web", "gizmo", nil),
+		}, objs...)...,
+	)
+
+	r, err := NewReader(client, disco)
+	require.NoError(t, err)
+	return r
+}
+
+func TestReader_Read(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		want    []string
+		wantErr bool
+	}{
+		{
+			"namespace",
+			Options{Kinds: []string{"deployment", "service"}, Namespace: "web"},
+			[]string{"Deployment web/frontend", "Service web/backend", "Service web/frontend"},
+			false,
+		},
+		{
+			"all namespaces",
+			Options{Kinds: []string{"services"}, AllNamespaces: true},
+			[]string{"Service other/frontend", "Service web/backend", "Service web/frontend"},
+			false,
+		},
+		{
+			"label selector",
+			Options{Kinds: []string{"svc"}, AllNamespaces: true, LabelSelector: "app=frontend"},
+			[]string{"Service other/frontend", "Service web/frontend"},
+			false,
+		},
+		{
+			"cluster scoped kind ignores namespace",
+			Options{Kinds: []string{"Namespace"}, Namespace: "other"},
+			[]string{"Namespace /web"},
+			false,
+		},
+		{
+			"group qualified kind and duplicates",
+			Options{Kinds: []string{"deployments.apps", "deploy"}, Namespace: "web"},
+			[]string{"Deployment web/frontend"},
+			false,
+		},
+		{
+			"custom resource",
+			Options{Kinds: []string{"widget"}, Namespace: "web"},
+			[]string{"Widget web/gizmo"},
+			false,
+		},
+		{
+			"unknown kind",
+			Options{Kinds: []string{"service", "gadget"}, Namespace: "web"},
+			nil,
+			true,
+		},
+		{
+			"no kinds",
+			Options{Namespace: "web"},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := newTestReader(t).Read(context.Background(), tt.opts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, obj := range objs {
+				u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+				require.NoError(t, err)
+				o := unstructured.Unstructured{Object: u}
+				got = append(got, o.GetKind()+" "+o.GetNamespace()+"/"+o.GetName())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReader_Read_typedAndStripped(t *testing.T) {
+	objs, err := newTestReader(t).Read(context.Background(), Options{Kinds: []string{"deployment", "service", "widget"}, Namespace: "web"})
+	require.NoError(t, err)
+	require.Len(t, objs, 4)
+
+	deploy, ok := objs[0].(*appsv1.Deployment)
+	require.True(t, ok, "expected *appsv1.Deployment, got %T", objs[0])
+	assert.Equal(t, "apps/v1", deploy.APIVersion)
+	assert.Equal(t, "Deployment", deploy.Kind)
+	assert.Equal(t, map[string]string{"app": "frontend"}, deploy.Labels)
+	assert.Empty(t, deploy.UID)
+	assert.Empty(t, deploy.ResourceVersion)
+	assert.Empty(t, deploy.ManagedFields)
+	assert.True(t, deploy.CreationTimestamp.IsZero())
+	assert.Equal(t, appsv1.DeploymentStatus{}, deploy.Status)
+
+	_, ok = objs[1].(*corev1.Service)
+	assert.True(t, ok, "expected *corev1.Service, got %T", objs[1])
+
+	widget, ok := objs[3].(*unstructured.Unstructured)
+	require.True(t, ok, "expected *unstructured.Unstructured, got %T", objs[3])
+	assert.Equal(t, map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "gizmo", "namespace": "web"},
+	}, widget.Object)
+}
+
+func TestReader_Read_allocatedSpecFields(t *testing.T) {
+	withSpec := func(u *unstructured.Unstructured, spec map[string]interface{}) *unstructured.Unstructured {
+		u.Object["spec"] = spec
+		return u
+	}
+
+	r := newTestReader(t,
+		withSpec(newObject("v1", "Service", "cache", "redis", nil), map[string]interface{}{
+			"type":           "ClusterIP",
+			"clusterIP":      "10.96.12.34",
+			"clusterIPs":     []interface{}{"10.96.12.34"},
+			"ipFamilies":     []interface{}{"IPv4"},
+			"ipFamilyPolicy": "SingleStack",
+			"selector":       map[string]interface{}{"app": "redis"},
+			"ports":          []interface{}{map[string]interface{}{"port": int64(6379)}},
+		}),
+		withSpec(newObject("v1", "Service", "cache", "redis-headless", nil), map[string]interface{}{
+			"clusterIP":  "None",
+			"clusterIPs": []interface{}{"None"},
+			"selector":   map[string]interface{}{"app": "redis"},
+		}),
+		withSpec(newObject("v1", "Service", "cache", "redis-lb", nil), map[string]interface{}{
+			"type":                  "LoadBalancer",
+			"externalTrafficPolicy": "Local",
+			"healthCheckNodePort":   int64(32000),
+			"selector":              map[string]interface{}{"app": "redis"},
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(6379), "nodePort": int64(31379)},
+			},
+		}),
+		withSpec(newObject("v1", "PersistentVolumeClaim", "cache", "data", nil), map[string]interface{}{
+			"accessModes": []interface{}{"ReadWriteOnce"},
+			"volumeName":  "pvc-0b6b9c2a-3f7e-4c43-8a43-1a2b3c4d5e6f",
+		}),
+	)
+
+	objs, err := r.Read(context.Background(), Options{Kinds: []string{"service", "pvc"}, Namespace: "cache"})
+	require.NoError(t, err)
+	require.Len(t, objs, 4)
+
+	svc, ok := objs[0].(*corev1.Service)
+	require.True(t, ok, "expected *corev1.Service, got %T", objs[0])
+	assert.Equal(t, "redis", svc.Name)
+	assert.Empty(t, svc.Spec.ClusterIP)
+	assert.Empty(t, svc.Spec.ClusterIPs)
+	assert.Empty(t, svc.Spec.IPFamilies)
+	assert.Nil(t, svc.Spec.IPFamilyPolicy)
+	assert.Equal(t, map[string]string{"app": "redis"}, svc.Spec.Selector)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, svc.Spec.Type)
+
+	headless, ok := objs[1].(*corev1.Service)
+	require.True(t, ok, "expected *corev1.Service, got %T", objs[1])
+	assert.Equal(t, "None", headless.Spec.ClusterIP, "the cluster IP of a headless Service is set by users")
+
+	lb, ok := objs[2].(*corev1.Service)
+	require.True(t, ok, "expected *corev1.Service, got %T", objs[2])
+	assert.Zero(t, lb.Spec.HealthCheckNodePort)
+	if assert.Len(t, lb.Spec.Ports, 1) {
+		assert.Equal(t, int32(6379), lb.Spec.Ports[0].Port)
+		assert.Zero(t, lb.Spec.Ports[0].NodePort)
+	}
+
+	pvc, ok := objs[3].(*corev1.PersistentVolumeClaim)
+	require.True(t, ok, "expected *corev1.PersistentVolumeClaim, got %T", objs[3])
+	assert.Empty(t, pvc.Spec.VolumeName)
+	assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, pvc.Spec.AccessModes)
+}
+
+func Test_removeJobFields(t *testing.T) {
+	job := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"batch.kubernetes.io/controller-uid": "0b6b9c2a"},
+			},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{
+						"app":                                "migrate",
+						"batch.kubernetes.io/controller-uid": "0b6b9c2a",
+						"batch.kubernetes.io/job-name":       "migrate",
+						"controller-uid":                     "0b6b9c2a",
+						"job-name":                           "migrate",
+					},
+				},
+			},
+		},
+	}
+	removeJobFields(job)
+
+	assert.Equal(t, map[string]interface{}{
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{"app": "migrate"},
+			},
+		},
+	}, job["spec"])
+
+	manual := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"manualSelector": true,
+			"selector":       map[string]interface{}{"matchLabels": map[string]interface{}{"app": "migrate"}},
+		},
+	}
+	removeJobFields(manual)
+	assert.Contains(t, manual["spec"], "selector")
+}
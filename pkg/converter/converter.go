@@ -0,0 +1,349 @@
+// Package converter converts Kubernetes API Objects to Terraform HCL configuration
+// for the Terraform Kubernetes Provider.
+package converter
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl/hcl/printer"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/mitchellh/reflectwalk"
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/sl1pm4t/k2tf/pkg/metafilter"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Options configures a Converter
+type Options struct {
+	// IncludeUnsupported includes attributes and blocks that are not found in
+	// the Terraform provider schema in the generated config.
+	IncludeUnsupported bool
+
+	// ManifestUnsupported renders kinds that are not supported by the Terraform provider
+	// (e.g. Custom Resources) as kubernetes_manifest resources, instead of skipping them.
+	ManifestUnsupported bool
+
+	// References replaces the names of other objects in the same batch (namespaces, config maps, secrets etc.)
+	// with references to the matching Terraform resources.
+	References bool
+
+	// SecretVariables replaces the values of Secret data with references to generated sensitive variables,
+	// so that secret values are not written to the generated config.
+	SecretVariables bool
+
+	// StripDefaults excludes attributes that are set to their default value, either from the Terraform provider
+	// schema or the Kubernetes API, e.g. dns_policy = "ClusterFirst" or revision_history_limit = 10.
+	StripDefaults bool
+
+	// LastApplied converts the configuration stored in the kubectl.kubernetes.io/last-applied-configuration
+	// annotation instead of the object itself, when the annotation is present. The annotation holds the
+	// configuration as written by the user, without the defaults and mutations of the API server.
+	LastApplied bool
+
+	// MetadataFilter optionally removes metadata managed by kubectl, controllers or the API server
+	// (e.g. managedFields or the last-applied-configuration annotation) from objects before they are converted.
+	// Objects are modified in place.
+	MetadataFilter *metafilter.Filter
+
+	// ForEach collapses near-identical resources of the same type (e.g. the same Deployment per tenant) into a
+	// single resource with for_each over a generated local map, that holds the values that differ.
+	ForEach bool
+
+	// ForEachMaxDiffs is the maximum number of distinct values that may differ between resources collapsed by
+	// ForEach. Defaults to DefaultForEachMaxDiffs.
+	ForEachMaxDiffs int
+
+	// Parameterize lifts literal values at DefaultParameterPaths (e.g. namespaces, images, replicas, resource
+	// limits and labels) that are used by more than one resource into local values, and references them from
+	// the resources instead.
+	Parameterize bool
+
+	// ParameterPaths lists attribute paths, e.g. spec.template.spec.container.image, whose literal values are
+	// always lifted into local values. See DefaultParameterPaths for the syntax.
+	ParameterPaths []string
+
+	// ParameterVariables lifts values into variables with a default value, instead of local values, when
+	// Parameterize or ParameterPaths is set.
+	ParameterVariables bool
+
+	// ImportBlocks appends a Terraform 1.5+ import block for each generated resource.
+	ImportBlocks bool
+
+	// TF12Format formats the generated config with the Terraform 0.12+ (HCL2) formatter.
+	// HCL2 is always used when the generated config contains expressions, e.g. when
+	// References, SecretVariables, ForEach, Parameterize, ParameterPaths or ImportBlocks are enabled.
+	TF12Format bool
+
+	// Source optionally returns the location an object was read from, e.g. a file name or Helm chart template.
+	// When set, the location is written as a comment above the generated resource.
+	Source func(runtime.Object) string
+}
+
+// Converter converts Kubernetes API Objects to Terraform HCL configuration
+type Converter struct {
+	opts Options
+}
+
+// New returns a Converter configured with the given options
+func New(opts Options) *Converter {
+	return &Converter{
+		opts: opts,
+	}
+}
+
+// SkippedField describes a Kubernetes object field that was excluded from the generated config
+// because it's not supported by the Terraform provider schema.
+type SkippedField struct {
+	// FieldPath is the path of the field in the Kubernetes API Object
+	// e.g. Namespace.Spec.Finalizers
+	FieldPath string `json:"field"`
+	// SchemaPath is the path of the matching attribute in the Terraform schema
+	// e.g. kubernetes_namespace.spec.finalizers
+	SchemaPath string `json:"schema"`
+}
+
+// Result holds the outcome of converting a single Kubernetes API Object
+type Result struct {
+	// Object is the Kubernetes API Object that was converted
+	Object runtime.Object
+	// Input is the object passed to the Converter. It differs from Object when Object was decoded from the
+	// last-applied-configuration annotation of Input.
+	Input runtime.Object
+	// LastApplied is true when Object was decoded from the last-applied-configuration annotation
+	LastApplied bool
+	// ResourceType is the generated Terraform resource type, e.g. kubernetes_deployment
+	ResourceType string
+	// ResourceName is the generated Terraform resource name
+	ResourceName string
+	// HCL is the formatted Terraform config. It's empty when the object was skipped.
+	HCL []byte
+	// Config is the generated Terraform config, in a format-neutral representation that can be written in
+	// HCL or JSON syntax. It's nil when the object was skipped.
+	Config *tfconfig.Body
+	// Skipped is true when the object kind is not supported by the Terraform provider,
+	// and no config was generated.
+	Skipped bool
+	// Warnings raised while converting the object
+	Warnings []string
+	// SkippedFields lists the object fields that were excluded from the generated config
+	SkippedFields []SkippedField
+	// SecretVariables lists the sensitive variables generated to hold Secret values
+	SecretVariables []SecretVariable
+	// DefaultFields lists the object fields that were excluded from the generated config by
+	// Options.StripDefaults, because they are set to their default value
+	DefaultFields []SkippedField
+	// ForEachKey is the for_each key of the resource, when it was collapsed with near-identical resources by
+	// Options.ForEach. The config of the group is held by the first Result of the group, HCL and Config of
+	// the other Results are empty.
+	ForEachKey string
+	// Parameters lists the local values or variables referenced by the resource, that were lifted by
+	// Options.Parameterize or Options.ParameterPaths. Their definitions are held by the config of the first
+	// Result that references them.
+	Parameters []*Parameter
+}
+
+// Address returns the address of the generated Terraform resource
+// e.g. kubernetes_deployment.backend_api, or kubernetes_deployment.tenant["tenant_a"] when collapsed by
+// Options.ForEach
+func (r *Result) Address() string {
+	if r.Skipped {
+		return ""
+	}
+	if r.ForEachKey != "" {
+		return fmt.Sprintf("%s.%s[%q]", r.ResourceType, r.ResourceName, r.ForEachKey)
+	}
+	return r.ResourceType + "." + r.ResourceName
+}
+
+// Convert converts a batch of Kubernetes API Objects.
+// A Result is returned for every object, in the same order as objs. Errors for
+// individual objects are accumulated and returned together.
+func (c *Converter) Convert(objs []runtime.Object) ([]*Result, error) {
+	var result error
+
+	var refs *ReferenceIndex
+	if c.opts.References {
+		refs = NewReferenceIndex(objs)
+	}
+
+	// secret variable names must be unique across the batch
+	secretNames := map[string]bool{}
+
+	results := make([]*Result, 0, len(objs))
+	for i, obj := range objs {
+		r, err := c.convertObject(obj, refs, secretNames)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("error converting object #%d: %w", i, err))
+		}
+		results = append(results, r)
+	}
+
+	if c.opts.ForEach {
+		c.collapseForEach(results)
+	}
+
+	if c.opts.Parameterize || len(c.opts.ParameterPaths) > 0 {
+		if err := c.parameterize(results); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return results, result
+}
+
+// ConvertObject converts a single Kubernetes API Object.
+// References are not supported when converting a single object.
+func (c *Converter) ConvertObject(obj runtime.Object) (*Result, error) {
+	return c.convertObject(obj, nil, map[string]bool{})
+}
+
+func (c *Converter) convertObject(obj runtime.Object, refs *ReferenceIndex, secretNames map[string]bool) (*Result, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("obj cannot be nil")
+	}
+
+	r := &Result{
+		Object: obj,
+		Input:  obj,
+	}
+
+	if c.opts.LastApplied {
+		obj = c.lastApplied(r)
+	}
+
+	c.opts.MetadataFilter.Apply(obj)
+
+	body := tfconfig.NewBody()
+
+	if tfkschema.IsKubernetesKindSupported(obj) {
+		r.ResourceType = tfkschema.ToTerraformResourceType(obj)
+		r.ResourceName = tfkschema.ToTerraformResourceName(obj)
+
+		w, err := c.writeObject(obj, body)
+		if err != nil {
+			return r, err
+		}
+		r.SkippedFields = w.SkippedFields()
+		r.DefaultFields = w.DefaultFields()
+		for _, sf := range r.SkippedFields {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("excluding attribute [%s] not found in Terraform schema", sf.SchemaPath))
+		}
+
+		if refs != nil {
+			refs.Apply(obj, body)
+		}
+
+		if c.opts.SecretVariables {
+			r.SecretVariables, err = WriteSecretVariables(obj, body, secretNames)
+			if err != nil {
+				return r, err
+			}
+		}
+
+	} else if c.opts.ManifestUnsupported {
+		r.ResourceType = manifestResourceType
+		r.ResourceName = ManifestResourceName(obj)
+
+		if err := WriteManifest(obj, body); err != nil {
+			return r, err
+		}
+
+	} else {
+		kind := k8sutils.TypeMeta(obj).Kind
+		r.Skipped = true
+		r.Warnings = append(r.Warnings, fmt.Sprintf("skipping API object, kind [%s] not supported by Terraform provider", kind))
+		return r, nil
+	}
+
+	if c.opts.ImportBlocks {
+		if err := WriteImportBlock(obj, body); err != nil {
+			return r, err
+		}
+	}
+
+	if c.opts.Source != nil {
+		if src := c.opts.Source(r.Input); src != "" {
+			body.Blocks()[0].Comment = "Source: " + src
+		}
+	}
+
+	r.Config = body
+	r.HCL = c.format(body.HCL())
+
+	return r, nil
+}
+
+// lastApplied returns the object decoded from the last-applied-configuration annotation of r.Input, updating r,
+// or r.Input if there's no such annotation. The source used is logged for each object.
+func (c *Converter) lastApplied(r *Result) runtime.Object {
+	om := k8sutils.ObjectMeta(r.Input)
+	l := log.With().
+		Str("kind", k8sutils.TypeMeta(r.Input).Kind).
+		Str("namespace", om.Namespace).
+		Str("name", om.Name).
+		Logger()
+
+	applied, err := LastAppliedConfiguration(r.Input)
+	if err != nil {
+		l.Warn().Err(err).Msg("converting live object, last-applied-configuration could not be decoded")
+		r.Warnings = append(r.Warnings, err.Error())
+		return r.Input
+	}
+	if applied == nil {
+		l.Info().Msg("converting live object, no last-applied-configuration annotation")
+		return r.Input
+	}
+
+	l.Info().Msg("converting last-applied-configuration")
+	r.Object = applied
+	r.LastApplied = true
+	return applied
+}
+
+// WriteObject converts a Kubernetes runtime.Object to HCL, appending the generated resource block to dst.
+// It returns the number of warnings raised during the conversion.
+func (c *Converter) WriteObject(obj runtime.Object, dst *hclwrite.Body) (int, error) {
+	body := tfconfig.NewBody()
+	w, err := c.writeObject(obj, body)
+	if err != nil {
+		return 0, err
+	}
+	body.WriteHCL(dst)
+
+	return w.WarnCount(), nil
+}
+
+func (c *Converter) writeObject(obj runtime.Object, dst *tfconfig.Body) (*ObjectWalker, error) {
+	w, err := NewObjectWalker(obj, dst)
+	if err != nil {
+		return nil, err
+	}
+	w.IncludeUnsupported = c.opts.IncludeUnsupported
+	w.StripDefaults = c.opts.StripDefaults
+
+	if err := reflectwalk.Walk(obj, w); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// format formats the generated HCL with the configured formatter
+func (c *Converter) format(in []byte) []byte {
+	if c.opts.TF12Format || c.opts.References || c.opts.SecretVariables || c.opts.ForEach || c.opts.Parameterize ||
+		len(c.opts.ParameterPaths) > 0 || c.opts.ImportBlocks {
+		return hclwrite.Format(in)
+	}
+
+	result, err := printer.Format(in)
+	if err != nil {
+		log.Error().Err(err).Msg("could not format object")
+		return in
+	}
+
+	return result
+}
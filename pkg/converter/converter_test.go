@@ -0,0 +1,166 @@
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"github.com/sl1pm4t/k2tf/pkg/metafilter"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func testParseFixtures(t *testing.T, fileparts ...[]string) []runtime.Object {
+	var objs []runtime.Object
+	for _, parts := range fileparts {
+		parsed, err := k8sparser.ParseYAML(strings.NewReader(testLoadFile(t, parts...)))
+		require.NoError(t, err)
+		objs = append(objs, parsed...)
+	}
+	return objs
+}
+
+func TestConverter_Convert(t *testing.T) {
+	objs := testParseFixtures(t,
+		[]string{"../../test-fixtures", "configMap.yaml"},
+		[]string{"../../test-fixtures", "namespace_w_spec.yaml"},
+		[]string{"../../test-fixtures", "manifest", "cronTab.yaml"},
+	)
+
+	tests := []struct {
+		name        string
+		opts        Options
+		wantSkipped []bool
+		wantAddrs   []string
+	}{
+		{
+			"default",
+			Options{},
+			[]bool{false, false, true},
+			[]string{"kubernetes_config_map.foo_config_map", "kubernetes_namespace.cert_manager", ""},
+		},
+		{
+			"manifest",
+			Options{ManifestUnsupported: true},
+			[]bool{false, false, false},
+			[]string{"kubernetes_config_map.foo_config_map", "kubernetes_namespace.cert_manager", "kubernetes_manifest.cron_tab_my_new_cron_object"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := New(tt.opts).Convert(objs)
+			require.NoError(t, err)
+			require.Len(t, results, len(objs))
+
+			for i, r := range results {
+				assert.Equal(t, tt.wantSkipped[i], r.Skipped)
+				assert.Equal(t, tt.wantAddrs[i], r.Address())
+				assert.Equal(t, r.Skipped, len(r.HCL) == 0)
+			}
+
+			// the namespace spec is not supported by the Terraform provider
+			assert.Equal(t, []SkippedField{{"Namespace.Spec", "kubernetes_namespace.spec"}}, results[1].SkippedFields)
+			assert.Len(t, results[1].Warnings, 1)
+		})
+	}
+}
+
+func TestConverter_ConvertObject(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "configMap.yaml"})
+
+	r, err := New(Options{ImportBlocks: true}).ConvertObject(objs[0])
+	require.NoError(t, err)
+
+	assert.Contains(t, string(r.HCL), `resource "kubernetes_config_map" "foo_config_map" {`)
+	assert.Contains(t, string(r.HCL), "to = kubernetes_config_map.foo_config_map")
+}
+
+func TestConverter_ConvertObject_nil(t *testing.T) {
+	_, err := New(Options{}).ConvertObject(nil)
+	assert.Error(t, err)
+}
+
+func TestConverter_ConvertObject_source(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "configMap.yaml"})
+
+	r, err := New(Options{
+		Source: func(runtime.Object) string { return "templates/configmap.yaml" },
+	}).ConvertObject(objs[0])
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(string(r.HCL), "# Source: templates/configmap.yaml\n"+`resource "kubernetes_config_map"`))
+}
+
+func TestConverter_ConvertObject_metadataFilter(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "defaults", "deployment.yaml"})
+
+	filter, err := metafilter.Parse(nil, true)
+	require.NoError(t, err)
+
+	r, err := New(Options{MetadataFilter: filter}).ConvertObject(objs[0])
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(r.HCL), "deployment.kubernetes.io/revision")
+	assert.Contains(t, string(r.HCL), `app = "web"`)
+}
+
+func TestConverter_ConvertObject_attributeSchema(t *testing.T) {
+	require.NoError(t, tfkschema.LoadProviderSchema("../../test-fixtures/providerSchemaAttributes.json"))
+	defer tfkschema.ResetProviderSchema()
+
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "service.yaml"})
+
+	r, err := New(Options{TF12Format: true}).ConvertObject(objs[0])
+	require.NoError(t, err)
+
+	// objects typed as attributes in the provider schema are written as attributes, not blocks
+	assert.Equal(t, `resource "kubernetes_service" "nginx" {
+  metadata = {
+    labels = {
+      app = "nginx"
+    }
+    name = "nginx"
+  }
+  spec {
+    port = [{
+      name = "web"
+      port = 80
+    }]
+    selector = {
+      app = "nginx"
+    }
+    cluster_ip   = "None"
+    external_ips = ["192.168.10.2"]
+  }
+}
+`, string(r.HCL))
+}
+
+func TestConverter_ConvertObject_json(t *testing.T) {
+	tests := []string{"deployment", "configMap", "service"}
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			objs := testParseFixtures(t, []string{"../../test-fixtures", name + ".yaml"})
+
+			r, err := New(Options{ImportBlocks: true}).ConvertObject(objs[0])
+			require.NoError(t, err)
+
+			content, err := json.MarshalIndent(r.Config, "", "  ")
+			require.NoError(t, err)
+
+			// Read our golden file (or optionally write if env var is set)
+			goldenFile := filepath.Join("../../test-fixtures", "json", name+".tf.json.golden")
+			if update {
+				os.WriteFile(goldenFile, append(content, '\n'), 0644)
+			}
+			expected := testLoadFile(t, goldenFile)
+
+			assert.JSONEq(t, expected, string(content))
+		})
+	}
+}
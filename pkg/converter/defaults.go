@@ -0,0 +1,183 @@
+package converter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/zclconf/go-cty/cty"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// apiDefaultFunc returns the value the Kubernetes API server sets on a field of the struct parent when it's omitted
+type apiDefaultFunc func(parent reflect.Value) interface{}
+
+func constant(v interface{}) apiDefaultFunc {
+	return func(reflect.Value) interface{} { return v }
+}
+
+// apiDefaults lists Kubernetes API defaults of commonly exported fields, keyed by API struct type and field name.
+// They are used for attributes that have no default in the Terraform provider schema.
+var apiDefaults = map[reflect.Type]map[string]apiDefaultFunc{
+	reflect.TypeOf(corev1.Container{}): {
+		"ImagePullPolicy":          defaultImagePullPolicy,
+		"TerminationMessagePath":   constant(corev1.TerminationMessagePathDefault),
+		"TerminationMessagePolicy": constant(corev1.TerminationMessageReadFile),
+	},
+	reflect.TypeOf(corev1.ContainerPort{}): {
+		"Protocol": constant(corev1.ProtocolTCP),
+	},
+	reflect.TypeOf(corev1.PodSpec{}): {
+		"DNSPolicy":                     constant(corev1.DNSClusterFirst),
+		"EnableServiceLinks":            constant(corev1.DefaultEnableServiceLinks),
+		"RestartPolicy":                 constant(corev1.RestartPolicyAlways),
+		"SchedulerName":                 constant(corev1.DefaultSchedulerName),
+		"TerminationGracePeriodSeconds": constant(corev1.DefaultTerminationGracePeriodSeconds),
+	},
+	reflect.TypeOf(corev1.Probe{}): {
+		"TimeoutSeconds":   constant(1),
+		"PeriodSeconds":    constant(10),
+		"SuccessThreshold": constant(1),
+		"FailureThreshold": constant(3),
+	},
+	reflect.TypeOf(corev1.HTTPGetAction{}): {
+		"Path":   constant("/"),
+		"Scheme": constant(corev1.URISchemeHTTP),
+	},
+	reflect.TypeOf(corev1.ObjectFieldSelector{}): {
+		"APIVersion": constant("v1"),
+	},
+	reflect.TypeOf(corev1.ConfigMapVolumeSource{}): {
+		"DefaultMode": constant(corev1.ConfigMapVolumeSourceDefaultMode),
+	},
+	reflect.TypeOf(corev1.SecretVolumeSource{}): {
+		"DefaultMode": constant(corev1.SecretVolumeSourceDefaultMode),
+	},
+	reflect.TypeOf(corev1.ServicePort{}): {
+		"Protocol": constant(corev1.ProtocolTCP),
+	},
+	reflect.TypeOf(corev1.ServiceSpec{}): {
+		"SessionAffinity": constant(corev1.ServiceAffinityNone),
+		"Type":            constant(corev1.ServiceTypeClusterIP),
+	},
+	reflect.TypeOf(corev1.PersistentVolumeClaimSpec{}): {
+		"VolumeMode": constant(corev1.PersistentVolumeFilesystem),
+	},
+	reflect.TypeOf(appsv1.DeploymentSpec{}): {
+		"Replicas":                constant(1),
+		"RevisionHistoryLimit":    constant(10),
+		"ProgressDeadlineSeconds": constant(600),
+	},
+	reflect.TypeOf(appsv1.RollingUpdateDeployment{}): {
+		"MaxSurge":       constant(intstr.FromString("25%")),
+		"MaxUnavailable": constant(intstr.FromString("25%")),
+	},
+	reflect.TypeOf(appsv1.DeploymentStrategy{}): {
+		"Type": constant(appsv1.RollingUpdateDeploymentStrategyType),
+	},
+	reflect.TypeOf(appsv1.StatefulSetSpec{}): {
+		"Replicas":             constant(1),
+		"RevisionHistoryLimit": constant(10),
+		"PodManagementPolicy":  constant(appsv1.OrderedReadyPodManagement),
+	},
+	reflect.TypeOf(appsv1.StatefulSetUpdateStrategy{}): {
+		"Type": constant(appsv1.RollingUpdateStatefulSetStrategyType),
+	},
+	reflect.TypeOf(appsv1.DaemonSetSpec{}): {
+		"RevisionHistoryLimit": constant(10),
+	},
+	reflect.TypeOf(appsv1.RollingUpdateDaemonSet{}): {
+		"MaxUnavailable": constant(intstr.FromInt(1)),
+	},
+	reflect.TypeOf(appsv1.DaemonSetUpdateStrategy{}): {
+		"Type": constant(appsv1.RollingUpdateDaemonSetStrategyType),
+	},
+	reflect.TypeOf(batchv1.JobSpec{}): {
+		"BackoffLimit":   constant(6),
+		"CompletionMode": constant(batchv1.NonIndexedCompletion),
+	},
+	reflect.TypeOf(batchv1.CronJobSpec{}): {
+		"ConcurrencyPolicy":          constant(batchv1.AllowConcurrent),
+		"SuccessfulJobsHistoryLimit": constant(3),
+		"FailedJobsHistoryLimit":     constant(1),
+	},
+	reflect.TypeOf(batchv1beta1.CronJobSpec{}): {
+		"ConcurrencyPolicy":          constant(batchv1beta1.AllowConcurrent),
+		"SuccessfulJobsHistoryLimit": constant(3),
+		"FailedJobsHistoryLimit":     constant(1),
+	},
+}
+
+// defaultImagePullPolicy returns the default imagePullPolicy of a Container, which depends on the image tag
+func defaultImagePullPolicy(container reflect.Value) interface{} {
+	image := container.FieldByName("Image").String()
+	if strings.Contains(image, "@") {
+		// pinned to a digest
+		return corev1.PullIfNotPresent
+	}
+
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i == -1 || name[i+1:] == "latest" {
+		return corev1.PullAlways
+	}
+	return corev1.PullIfNotPresent
+}
+
+// stripDefault returns true if StripDefaults is set, and the value v of the current field, converted to the
+// attribute value val, is the default value of the named attribute of block. The field is recorded in DefaultFields.
+func (w *ObjectWalker) stripDefault(block *hclBlock, name string, v reflect.Value, val cty.Value) bool {
+	if !w.StripDefaults || !w.isDefault(block, name, v, val) {
+		return false
+	}
+
+	w.debugf("stripping %s = %s, default value", name, ctyString(val))
+	w.defaultFields = append(w.defaultFields, SkippedField{
+		FieldPath:  block.FullFieldName() + "." + w.field().Name,
+		SchemaPath: block.FullSchemaName() + "." + name,
+	})
+	return true
+}
+
+// isDefault returns true if v, converted to val, is the default value of the named attribute of block.
+// The Terraform provider schema default takes precedence, as Terraform applies it when the attribute is omitted.
+// Kubernetes API defaults are used for attributes without a provider default.
+func (w *ObjectWalker) isDefault(block *hclBlock, name string, v reflect.Value, val cty.Value) bool {
+	if def, ok := tfkschema.AttributeDefault(block.FullSchemaName() + "." + name); ok {
+		return fmt.Sprint(def) == ctyString(val)
+	}
+
+	parent := block.value
+	if !parent.IsValid() {
+		return false
+	}
+
+	def, ok := apiDefaults[parent.Type()][w.field().Name]
+	if !ok {
+		return false
+	}
+
+	want := def(parent)
+	return reflect.DeepEqual(want, v.Interface()) || fmt.Sprint(want) == fmt.Sprint(v.Interface())
+}
+
+// ctyString returns the string representation of a primitive cty value
+func ctyString(v cty.Value) string {
+	if v.IsNull() || !v.IsKnown() {
+		return ""
+	}
+
+	switch v.Type() {
+	case cty.String:
+		return v.AsString()
+	case cty.Number:
+		return v.AsBigFloat().Text('f', -1)
+	case cty.Bool:
+		return fmt.Sprint(v.True())
+	}
+	return v.GoString()
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConverter_StripDefaults(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "defaults", "deployment.yaml"})
+
+	r, err := New(Options{StripDefaults: true}).ConvertObject(objs[0])
+	require.NoError(t, err)
+
+	// Read our golden file (or optionally write if env var is set)
+	goldenFile := filepath.Join("../../test-fixtures", "defaults", "deployment.tf.golden")
+	if update {
+		os.WriteFile(goldenFile, r.HCL, 0644)
+	}
+	expected := testLoadFile(t, goldenFile)
+
+	assert.Equal(t, expected, string(r.HCL), "should be equal")
+	assert.Contains(t, r.DefaultFields, SkippedField{
+		FieldPath:  "Deployment.Spec.RevisionHistoryLimit",
+		SchemaPath: "kubernetes_deployment.spec.revision_history_limit",
+	})
+	assert.Contains(t, r.DefaultFields, SkippedField{
+		FieldPath:  "Deployment.Spec.Template.Spec.Containers.TerminationMessagePolicy",
+		SchemaPath: "kubernetes_deployment.spec.template.spec.container.termination_message_policy",
+	})
+
+	// defaults are kept without StripDefaults
+	r, err = New(Options{}).ConvertObject(objs[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(r.HCL), "revision_history_limit")
+	assert.Empty(t, r.DefaultFields)
+}
+
+func Test_defaultImagePullPolicy(t *testing.T) {
+	tests := []struct {
+		image string
+		want  corev1.PullPolicy
+	}{
+		{"nginx", corev1.PullAlways},
+		{"nginx:latest", corev1.PullAlways},
+		{"nginx:1.25", corev1.PullIfNotPresent},
+		{"localhost:5000/nginx", corev1.PullAlways},
+		{"localhost:5000/nginx:1.25", corev1.PullIfNotPresent},
+		{"nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31", corev1.PullIfNotPresent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got := defaultImagePullPolicy(reflect.ValueOf(corev1.Container{Image: tt.image}))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,490 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DefaultForEachMaxDiffs is the default for Options.ForEachMaxDiffs
+const DefaultForEachMaxDiffs = 3
+
+// forEachMember is a resource that's part of a for_each group
+type forEachMember struct {
+	result   *Result
+	resource *tfconfig.Block
+	leaves   []leaf
+}
+
+// forEachValue is a value that differs between the members of a group, and is moved to the local map
+type forEachValue struct {
+	name  string
+	exprs []tfconfig.Expression
+	// leaves are the indexes of the leaves of each member that hold the value
+	leaves []int
+}
+
+// collapseForEach replaces groups of near-identical resources with a single resource using for_each over a
+// local map, holding the values that differ between the resources.
+//
+// Resources are grouped when they have the same resource type, the same attributes and nested blocks, and at
+// most Options.ForEachMaxDiffs distinct differing values. The first Result of each group holds the generated
+// config of the group, the config of the other Results is cleared. ForEachKey is set on all Results of a group.
+func (c *Converter) collapseForEach(results []*Result) {
+	maxDiffs := c.opts.ForEachMaxDiffs
+	if maxDiffs == 0 {
+		maxDiffs = DefaultForEachMaxDiffs
+	}
+
+	var keys []string
+	groups := map[string][]*forEachMember{}
+	names := map[string]bool{}
+	renames := map[string]hcl.Traversal{}
+	for _, r := range results {
+		if r == nil || r.Skipped {
+			continue
+		}
+		names[r.Address()] = true
+
+		if r.ResourceType == manifestResourceType || len(r.SecretVariables) > 0 || r.Config == nil {
+			continue
+		}
+
+		resource := resourceBlock(r)
+		if resource == nil {
+			continue
+		}
+
+		var shape strings.Builder
+		m := &forEachMember{
+			result:   r,
+			resource: resource,
+			leaves:   walkLeaves(resource.Body, nil, &shape),
+		}
+
+		key := r.ResourceType + "\n" + shape.String()
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], m)
+	}
+
+	for _, key := range keys {
+		members := groups[key]
+		if len(members) < 2 {
+			continue
+		}
+		if !uniqueResourceNames(members) {
+			// e.g. objects with the same name in different namespaces, the instances would overwrite each other
+			log.Debug().
+				Str("type", members[0].result.ResourceType).
+				Msgf("not collapsing %d resources, their resource names are not unique", len(members))
+			continue
+		}
+
+		values := differingValues(members)
+		if len(values) == 0 || len(values) > maxDiffs {
+			log.Debug().
+				Str("type", members[0].result.ResourceType).
+				Msgf("not collapsing %d resources, %d values differ", len(members), len(values))
+			continue
+		}
+
+		c.collapse(members, values, names, renames)
+	}
+
+	if len(renames) == 0 {
+		return
+	}
+	// references to the collapsed resources, e.g. added by Options.References, must use the instance address
+	for _, r := range results {
+		if r != nil && r.Config != nil && rewriteReferences(r.Config, renames) {
+			r.HCL = c.format(r.Config.HCL())
+		}
+	}
+}
+
+// differingValues returns the values that differ between members, in order of their first appearance.
+// Values that differ in the same way (e.g. the app label of the metadata, selector and template) are
+// returned once.
+func differingValues(members []*forEachMember) []*forEachValue {
+	var values []*forEachValue
+
+leaves:
+	for i := range members[0].leaves {
+		exprs := make([]tfconfig.Expression, len(members))
+		same := true
+		for j, m := range members {
+			exprs[j] = m.leaves[i].expr
+			same = same && exprs[j].Equal(exprs[0])
+		}
+		if same {
+			continue
+		}
+
+		for _, v := range values {
+			if equalExprs(v.exprs, exprs) {
+				v.leaves = append(v.leaves, i)
+				continue leaves
+			}
+		}
+		values = append(values, &forEachValue{exprs: exprs, leaves: []int{i}})
+	}
+
+	nameValues(values, members[0].leaves)
+	return values
+}
+
+// nameValues names the attributes of the local map after the path of each value, using as few path elements
+// as required to make names unique, e.g. name, or labels_app and match_labels_app.
+func nameValues(values []*forEachValue, leaves []leaf) {
+	used := map[string]bool{}
+	for _, v := range values {
+		path := leaves[v.leaves[0]].path
+		for n := 1; n <= len(path); n++ {
+			name := identifier(strings.Join(path[len(path)-n:], "_"))
+			if !used[name] {
+				v.name = name
+				break
+			}
+		}
+		for i := 2; v.name == ""; i++ {
+			if name := fmt.Sprintf("%s_%d", identifier(path[len(path)-1]), i); !used[name] {
+				v.name = name
+			}
+		}
+		used[v.name] = true
+	}
+}
+
+// collapse replaces the config of members with a single resource with for_each
+// The address of each member is recorded in renames, mapped to its instance address.
+func (c *Converter) collapse(members []*forEachMember, values []*forEachValue, names map[string]bool, renames map[string]hcl.Traversal) {
+	first := members[0].result
+	name := groupResourceName(members, names)
+	names[first.ResourceType+"."+name] = true
+	localName := first.ResourceType + "_" + name
+
+	log.Info().
+		Str("type", first.ResourceType).
+		Str("name", name).
+		Msgf("collapsing %d resources into a single resource with for_each", len(members))
+
+	// the local map, keyed by the resource names of the members
+	elems := map[string]tfconfig.Expression{}
+	for i, m := range members {
+		attrs := map[string]tfconfig.Expression{}
+		for _, v := range values {
+			attrs[v.name] = v.exprs[i]
+		}
+		elems[m.result.ResourceName] = tfconfig.Expression{Object: attrs}
+	}
+	locals := tfconfig.NewBlock("locals", nil)
+	locals.Body.SetAttribute(localName, tfconfig.Expression{Object: elems})
+
+	// the resource, with differing values replaced by references to each.value
+	resource := members[0].resource
+	resource.Labels = []string{first.ResourceType, name}
+	resource.Body.PrependAttribute("for_each", tfconfig.TraversalExpr(hcl.Traversal{
+		hcl.TraverseRoot{Name: "local"},
+		hcl.TraverseAttr{Name: localName},
+	}))
+	for _, v := range values {
+		ref := tfconfig.TraversalExpr(hcl.Traversal{
+			hcl.TraverseRoot{Name: "each"},
+			hcl.TraverseAttr{Name: "value"},
+			hcl.TraverseAttr{Name: v.name},
+		})
+		for _, i := range v.leaves {
+			members[0].leaves[i].set(ref)
+		}
+	}
+
+	var sources []string
+	for _, m := range members {
+		if m.resource.Comment != "" {
+			sources = append(sources, strings.TrimPrefix(m.resource.Comment, "Source: "))
+		}
+	}
+	resource.Comment = ""
+	if len(sources) > 0 {
+		resource.Comment = "Source: " + strings.Join(sources, ", ")
+	}
+
+	body := tfconfig.NewBody()
+	body.AppendBlock(locals)
+	body.AppendBlock(resource)
+
+	for _, m := range members {
+		m.result.ForEachKey = m.result.ResourceName
+		renames[first.ResourceType+"."+m.result.ForEachKey] = hcl.Traversal{
+			hcl.TraverseRoot{Name: first.ResourceType},
+			hcl.TraverseAttr{Name: name},
+			hcl.TraverseIndex{Key: cty.StringVal(m.result.ForEachKey)},
+		}
+		m.result.ResourceName = name
+
+		for _, b := range m.result.Config.Blocks() {
+			if b.Type != "import" {
+				continue
+			}
+			// import each instance of the resource
+			to := hcl.Traversal{
+				hcl.TraverseRoot{Name: first.ResourceType},
+				hcl.TraverseAttr{Name: name},
+				hcl.TraverseIndex{Key: cty.StringVal(m.result.ForEachKey)},
+			}
+			b.Body.SetAttribute("to", tfconfig.Expression{Traversal: to, Static: true})
+			body.AppendBlock(b)
+		}
+
+		m.result.Config = nil
+		m.result.HCL = nil
+	}
+
+	first.Config = body
+	first.HCL = c.format(body.HCL())
+}
+
+// leaf is an attribute value, or a key of a map attribute value, of a generated resource
+type leaf struct {
+	// path of the value, e.g. [metadata labels app]
+	path []string
+	// mapKey is true when the value is an element of a map attribute, e.g. a label
+	mapKey bool
+	expr   tfconfig.Expression
+	// set replaces the value in the resource body
+	set func(tfconfig.Expression)
+}
+
+// walkLeaves returns the attribute values of body and its nested blocks as leaves, and writes the attribute
+// names and nested block types of body to shape. Maps of primitive values, such as labels, are split into a
+// leaf per key.
+func walkLeaves(body *tfconfig.Body, path []string, shape *strings.Builder) []leaf {
+	var leaves []leaf
+	for _, attr := range body.Attributes() {
+		attr := attr
+		attrPath := appendPath(path, attr.Name)
+
+		if keys, ok := primitiveMapKeys(attr.Expr); ok {
+			fmt.Fprintf(shape, "%s={%s};", attr.Name, strings.Join(keys, ","))
+			for _, k := range keys {
+				k := k
+				leaves = append(leaves, leaf{
+					path:   appendPath(attrPath, k),
+					mapKey: true,
+					expr:   tfconfig.ValueExpr(attr.Expr.Value.Index(cty.StringVal(k))),
+					set: func(e tfconfig.Expression) {
+						if attr.Expr.Object == nil {
+							attr.Expr = objectExpr(attr.Expr.Value)
+						}
+						attr.Expr.Object[k] = e
+					},
+				})
+			}
+			continue
+		}
+
+		fmt.Fprintf(shape, "%s;", attr.Name)
+		leaves = append(leaves, leaf{
+			path: attrPath,
+			expr: attr.Expr,
+			set:  func(e tfconfig.Expression) { attr.Expr = e },
+		})
+	}
+
+	for _, b := range body.Blocks() {
+		fmt.Fprintf(shape, "%s{", b.Type)
+		leaves = append(leaves, walkLeaves(b.Body, appendPath(path, b.Type), shape)...)
+		shape.WriteString("}")
+	}
+
+	return leaves
+}
+
+// rewriteReferences replaces references to the resource addresses in renames with the mapped addresses, in all
+// attributes of body and its nested blocks. It returns true when a reference was replaced.
+// e.g. kubernetes_config_map.tenant_a.metadata[0].name -> kubernetes_config_map.tenant["tenant_a"].metadata[0].name
+func rewriteReferences(body *tfconfig.Body, renames map[string]hcl.Traversal) bool {
+	changed := false
+	for _, attr := range body.Attributes() {
+		if rewriteExpr(&attr.Expr, renames) {
+			changed = true
+		}
+	}
+	for _, b := range body.Blocks() {
+		if rewriteReferences(b.Body, renames) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+func rewriteExpr(expr *tfconfig.Expression, renames map[string]hcl.Traversal) bool {
+	changed := false
+	for k, e := range expr.Object {
+		if rewriteExpr(&e, renames) {
+			expr.Object[k] = e
+			changed = true
+		}
+	}
+
+	t := expr.Traversal
+	if expr.Static || len(t) < 2 {
+		return changed
+	}
+	root, ok := t[0].(hcl.TraverseRoot)
+	if !ok {
+		return changed
+	}
+	attr, ok := t[1].(hcl.TraverseAttr)
+	if !ok {
+		return changed
+	}
+	prefix, ok := renames[root.Name+"."+attr.Name]
+	if !ok {
+		return changed
+	}
+
+	expr.Traversal = append(append(hcl.Traversal{}, prefix...), t[2:]...)
+	return true
+}
+
+// resourceBlock returns the resource block of the config generated for r
+func resourceBlock(r *Result) *tfconfig.Block {
+	for _, b := range r.Config.Blocks() {
+		if b.Type == "resource" && len(b.Labels) == 2 && b.Labels[0] == r.ResourceType && b.Labels[1] == r.ResourceName {
+			return b
+		}
+	}
+	return nil
+}
+
+// uniqueResourceNames returns true when the resource names of members, used as for_each keys, are unique
+func uniqueResourceNames(members []*forEachMember) bool {
+	seen := map[string]bool{}
+	for _, m := range members {
+		if seen[m.result.ResourceName] {
+			return false
+		}
+		seen[m.result.ResourceName] = true
+	}
+	return true
+}
+
+// groupResourceName returns the resource name for a group: the common prefix or suffix of the
+// resource names of the members, or the resource type, made unique within names.
+func groupResourceName(members []*forEachMember, names map[string]bool) string {
+	resourceNames := make([]string, 0, len(members))
+	for _, m := range members {
+		resourceNames = append(resourceNames, m.result.ResourceName)
+	}
+	ty := members[0].result.ResourceType
+
+	name := commonPrefix(resourceNames)
+	if name == "" {
+		name = commonSuffix(resourceNames)
+	}
+	if name == "" || !validIdentifier.MatchString(name) {
+		name = strings.TrimPrefix(ty, "kubernetes_")
+	}
+
+	unique := name
+	for i := 2; names[ty+"."+unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	return unique
+}
+
+// primitiveMapKeys returns the sorted keys of a map or object value whose elements are all primitive values
+func primitiveMapKeys(expr tfconfig.Expression) ([]string, bool) {
+	v := expr.Value
+	if expr.Traversal != nil || expr.Object != nil || v == cty.NilVal || v.IsNull() || !v.IsWhollyKnown() {
+		return nil, false
+	}
+	if !v.Type().IsMapType() && !v.Type().IsObjectType() {
+		return nil, false
+	}
+
+	var keys []string
+	for k, e := range v.AsValueMap() {
+		if !e.Type().IsPrimitiveType() {
+			return nil, false
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys, len(keys) > 0
+}
+
+// objectExpr converts a map or object value to an object expression, so that its elements can be replaced
+// with other expressions
+func objectExpr(v cty.Value) tfconfig.Expression {
+	attrs := map[string]tfconfig.Expression{}
+	for k, e := range v.AsValueMap() {
+		attrs[k] = tfconfig.ValueExpr(e)
+	}
+	return tfconfig.Expression{Object: attrs}
+}
+
+var (
+	validIdentifier   = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	invalidIdentChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+)
+
+// identifier converts s to a valid Terraform identifier, e.g. app.kubernetes.io/name -> app_kubernetes_io_name
+func identifier(s string) string {
+	s = strings.Trim(invalidIdentChars.ReplaceAllString(s, "_"), "_")
+	if s == "" || !validIdentifier.MatchString(s) {
+		s = "v_" + s
+	}
+	return strings.ToLower(s)
+}
+
+// commonPrefix returns the words that all of s start with, e.g. tenant for tenant_a_web and tenant_b_web
+func commonPrefix(s []string) string {
+	prefix := strings.Split(s[0], "_")
+	for _, e := range s[1:] {
+		words := strings.Split(e, "_")
+		n := 0
+		for n < len(prefix) && n < len(words) && prefix[n] == words[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return strings.Join(prefix, "_")
+}
+
+// commonSuffix returns the words that all of s end with, e.g. web for tenant_a_web and tenant_b_web
+func commonSuffix(s []string) string {
+	suffix := strings.Split(s[0], "_")
+	for _, e := range s[1:] {
+		words := strings.Split(e, "_")
+		n := 0
+		for n < len(suffix) && n < len(words) && suffix[len(suffix)-1-n] == words[len(words)-1-n] {
+			n++
+		}
+		suffix = suffix[len(suffix)-n:]
+	}
+	return strings.Join(suffix, "_")
+}
+
+func equalExprs(a, b []tfconfig.Expression) bool {
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func appendPath(path []string, elem string) []string {
+	return append(append([]string{}, path...), elem)
+}
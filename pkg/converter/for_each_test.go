@@ -0,0 +1,118 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverter_ForEach(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "for_each", "tenants.yaml"})
+
+	results, err := New(Options{ForEach: true}).Convert(objs)
+	require.NoError(t, err)
+
+	var addrs []string
+	var hcl []string
+	for _, r := range results {
+		addrs = append(addrs, r.Address())
+		if r.Config != nil {
+			hcl = append(hcl, string(r.HCL))
+		}
+	}
+
+	assert.Equal(t, []string{
+		`kubernetes_config_map.settings["acme_settings"]`,
+		`kubernetes_deployment.web["acme_web"]`,
+		`kubernetes_config_map.settings["globex_settings"]`,
+		`kubernetes_deployment.web["globex_web"]`,
+		`kubernetes_config_map.settings["initech_settings"]`,
+		`kubernetes_deployment.web["initech_web"]`,
+		`kubernetes_deployment.admin`,
+	}, addrs)
+
+	// Read our golden file (or optionally write if env var is set)
+	goldenFile := filepath.Join("../../test-fixtures", "for_each", "tenants.tf.golden")
+	if update {
+		os.WriteFile(goldenFile, []byte(strings.Join(hcl, "\n")), 0644)
+	}
+	expected := testLoadFile(t, goldenFile)
+
+	assert.Equal(t, expected, strings.Join(hcl, "\n"), "should be equal")
+}
+
+func TestConverter_ForEach_maxDiffs(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "for_each", "tenants.yaml"})
+
+	results, err := New(Options{ForEach: true, ForEachMaxDiffs: 2}).Convert(objs)
+	require.NoError(t, err)
+
+	for _, r := range results {
+		if r.ResourceType == "kubernetes_config_map" {
+			// name, team and tenant differ
+			assert.Empty(t, r.ForEachKey, r.Address())
+			assert.NotNil(t, r.Config, r.Address())
+		}
+	}
+}
+
+func TestConverter_ForEach_references(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "for_each", "references.yaml"})
+
+	results, err := New(Options{References: true, ForEach: true}).Convert(objs)
+	require.NoError(t, err)
+
+	var pod *Result
+	for _, r := range results {
+		if r.ResourceType == "kubernetes_pod" {
+			pod = r
+		}
+	}
+	require.NotNil(t, pod)
+	assert.Equal(t, `kubernetes_config_map.tenant["tenant_a"]`, results[0].Address())
+
+	hcl := string(pod.HCL)
+	assert.Contains(t, hcl, `kubernetes_config_map.tenant["tenant_a"].metadata[0].name`)
+	assert.NotContains(t, hcl, "kubernetes_config_map.tenant_a.")
+}
+
+func TestConverter_ForEach_namespaces(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "for_each", "namespaces.yaml"})
+
+	results, err := New(Options{ForEach: true}).Convert(objs)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// same-named objects in different namespaces have the same resource name, and are not collapsed
+	for _, r := range results {
+		assert.Empty(t, r.ForEachKey, r.Address())
+		assert.NotNil(t, r.Config, r.Address())
+	}
+	assert.Contains(t, string(results[0].HCL), `namespace = "tenant-a"`)
+	assert.Contains(t, string(results[1].HCL), `namespace = "tenant-b"`)
+}
+
+func Test_groupNames(t *testing.T) {
+	tests := []struct {
+		names      []string
+		wantPrefix string
+		wantSuffix string
+	}{
+		{[]string{"tenant_a_web", "tenant_b_web"}, "tenant", "web"},
+		{[]string{"web", "web_canary"}, "web", ""},
+		{[]string{"team_a", "teams_b"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.names, ","), func(t *testing.T) {
+			assert.Equal(t, tt.wantPrefix, commonPrefix(tt.names))
+			assert.Equal(t, tt.wantSuffix, commonSuffix(tt.names))
+		})
+	}
+
+	assert.Equal(t, "app_kubernetes_io_name", identifier("app.kubernetes.io/name"))
+	assert.Equal(t, "v_8080", identifier("8080"))
+}
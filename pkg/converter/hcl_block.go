@@ -1,15 +1,18 @@
-package main
+package converter
 
 import (
-	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"reflect"
 	"strings"
 
-	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/zclconf/go-cty/cty"
 )
 
-// hclBlock is a wrapper for hclwrite.Block that allows tagging some extra
+// hclBlock is a wrapper for tfconfig.Block that allows tagging some extra
 // metadata to each block.
 type hclBlock struct {
 	//
@@ -21,8 +24,14 @@ type hclBlock struct {
 	// The parent hclBlock to this hclBlock
 	parent *hclBlock
 
-	// The wrapped HCL block
-	hcl *hclwrite.Block
+	// The wrapped block
+	block *tfconfig.Block
+
+	// The ObjectWalker that opened this block
+	walker *ObjectWalker
+
+	// value is the Kubernetes API struct this block is generated from, if any
+	value reflect.Value
 
 	// hasValue means a child field of this block had a non-nil / non-zero value.
 	// If this is false when closeBlock() is called, the block won't be appended to
@@ -60,13 +69,13 @@ type hclBlock struct {
 // A child block is adding a sub-block, write HCL to:
 // - this hclBlock's hcl Body if this block is not inlined
 // - parent's HCL body if this block is "inlined"
-func (b *hclBlock) AppendBlock(hcl *hclwrite.Block) {
+func (b *hclBlock) AppendBlock(block *tfconfig.Block) {
 	if b.inlined {
 		// append to parent
-		b.parent.AppendBlock(hcl)
+		b.parent.AppendBlock(block)
 
 	} else {
-		b.hcl.Body().AppendBlock(hcl)
+		b.block.Body.AppendBlock(block)
 
 	}
 }
@@ -82,17 +91,61 @@ func (b *hclBlock) SetAttributeValue(name string, val cty.Value) {
 			b.hclMap[name] = val
 		}
 
-	} else if includeUnsupported || tfkschema.IsAttributeSupported(b.FullSchemaName()+"."+name) {
+	} else if b.walker.IncludeUnsupported || tfkschema.IsAttributeSupported(b.FullSchemaName()+"."+name) {
 		if b.inlined {
 			// append to parent
 			b.parent.SetAttributeValue(name, val)
 		} else {
-			b.hcl.Body().SetAttributeValue(name, val)
+			b.block.Body.SetAttributeValue(name, val)
 		}
 	} else {
 		log.Debugf("skipping attribute: %s - not supported by provider", name)
 
+		fieldName := b.FullFieldName()
+		if f := b.walker.field(); f != nil {
+			fieldName += "." + f.Name
+		}
+		b.walker.skip(fieldName, b.FullSchemaName()+"."+name)
+	}
+}
+
+// attributeSchema returns the Terraform schema of this block, if it's typed as an attribute holding an object or a
+// list of objects, e.g. in provider schemas loaded with tfkschema.LoadProviderSchema
+func (b *hclBlock) attributeSchema() *schema.Schema {
+	s := tfkschema.ResourceField(b.FullSchemaName())
+	if s == nil || s.ConfigMode != schema.SchemaConfigModeAttr {
+		return nil
+	}
+	return s
+}
+
+// objectValue returns the attribute values written to this block, as an object
+func (b *hclBlock) objectValue() cty.Value {
+	attrs := map[string]cty.Value{}
+	for _, attr := range b.block.Body.Attributes() {
+		if attr.Expr.Value != cty.NilVal {
+			attrs[attr.Name] = attr.Expr.Value
+		}
+	}
+	return cty.ObjectVal(attrs)
+}
+
+// appendObject sets the named attribute to val. When list is set, val is appended to the list of objects held by
+// the attribute instead.
+func (b *hclBlock) appendObject(name string, val cty.Value, list bool) {
+	if b.inlined {
+		b.parent.appendObject(name, val, list)
+		return
+	}
+
+	if list {
+		var elems []cty.Value
+		if attr := b.block.Body.GetAttribute(name); attr != nil && attr.Expr.Value != cty.NilVal && attr.Expr.Value.Type().IsTupleType() {
+			elems = attr.Expr.Value.AsValueSlice()
+		}
+		val = cty.TupleVal(append(elems, val))
 	}
+	b.SetAttributeValue(name, val)
 }
 
 func (b *hclBlock) FullSchemaName() string {
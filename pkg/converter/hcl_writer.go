@@ -1,4 +1,4 @@
-package main
+package converter
 
 import (
 	"fmt"
@@ -7,13 +7,13 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
 	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/rs/zerolog"
 
-	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/mitchellh/reflectwalk"
 	"github.com/rs/zerolog/log"
 	"github.com/zclconf/go-cty/cty"
@@ -22,26 +22,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
-// WriteObject converts a Kubernetes runtime.Object to HCL
-func WriteObject(obj runtime.Object, dst *hclwrite.Body) (int, error) {
-	w, err := NewObjectWalker(obj, dst)
-	if err != nil {
-		return 0, err
-	}
-	reflectwalk.Walk(obj, w)
-
-	return w.warnCount, nil
-}
-
 // ObjectWalker implements reflectwalk.Walker interfaces
 // It's used to "walk" the Kubernetes API Objects structure and generate
-// an HCL document based on the values defined.
+// Terraform configuration based on the values defined.
 type ObjectWalker struct {
 	// The Kubernetes API Object to be walked
 	RuntimeObject runtime.Object
 
-	// The HCL body where HCL blocks will be appended
-	dst *hclwrite.Body
+	// The body where the generated resource block will be appended
+	dst *tfconfig.Body
 
 	// Terraform resource type (e.g. kubernetes_pod)
 	resourceType string
@@ -68,11 +57,21 @@ type ObjectWalker struct {
 	// further processing for each element.
 	ignoreSliceElems bool
 	warnCount        int
+
+	// IncludeUnsupported includes attributes and blocks that are not found in the Terraform schema
+	IncludeUnsupported bool
+	// skippedFields records the attributes and blocks excluded from the generated HCL
+	skippedFields []SkippedField
+
+	// StripDefaults excludes attributes set to their Terraform provider or Kubernetes API default value
+	StripDefaults bool
+	// defaultFields records the attributes excluded because they are set to their default value
+	defaultFields []SkippedField
 }
 
 // NewObjectWalker returns a new ObjectWalker object
-// dst is the hclwrite.Body where HCL blocks will be appended.
-func NewObjectWalker(obj runtime.Object, dst *hclwrite.Body) (*ObjectWalker, error) {
+// dst is the tfconfig.Body where the generated resource block will be appended.
+func NewObjectWalker(obj runtime.Object, dst *tfconfig.Body) (*ObjectWalker, error) {
 	if obj == nil {
 		return nil, fmt.Errorf("obj cannot be nil")
 	}
@@ -179,20 +178,21 @@ func (w *ObjectWalker) sliceElemType() reflect.Type {
 // openBlock opens a new HCL resource block or sub-block
 // It creates a hclBlock object so we can track hierarchy of blocks
 // within the resource tree
-func (w *ObjectWalker) openBlock(name, fieldName string, hcl *hclwrite.Block) *hclBlock {
+func (w *ObjectWalker) openBlock(name, fieldName string, block *tfconfig.Block) *hclBlock {
 	w.debugf("opening hclBlock for field: %s", name)
 	b := &hclBlock{
 		name:      name,
 		fieldName: fieldName,
 		parent:    w.currentBlock,
-		hcl:       hcl,
+		block:     block,
+		walker:    w,
 	}
 
 	w.currentBlock = b
 	return b
 }
 
-// closeBlock writes the generated HCL to the hclwriter
+// closeBlock appends the generated block to its parent
 func (w *ObjectWalker) closeBlock() *hclBlock {
 	w.debugf("closing hclBlock: %s", w.currentBlock.name)
 
@@ -201,16 +201,17 @@ func (w *ObjectWalker) closeBlock() *hclBlock {
 
 	// TODO: move append logic to hcl_block to be consistent
 	if parent == nil {
-		// we are closing the top level block, write directly to HCL File
-		w.dst.AppendBlock(current.hcl)
+		// we are closing the top level block, write directly to the destination body
+		w.dst.AppendBlock(current.block)
 
 	} else {
 		if current.hasValue || tfkschema.IncludedOnZero(w.currentBlock.fieldName) || current.isRequired() {
-			if !includeUnsupported && current.unsupported {
+			if !w.IncludeUnsupported && current.unsupported {
 				// don't append this block or child blocks
 				w.warn().
 					Str("field", current.FullFieldName()).
 					Msgf("excluding attribute [%s] not found in Terraform schema", current.FullSchemaName())
+				w.skip(current.FullFieldName(), current.FullSchemaName())
 
 			} else {
 				// communicate back up the tree that we found a non-zero value
@@ -221,8 +222,12 @@ func (w *ObjectWalker) closeBlock() *hclBlock {
 						parent.SetAttributeValue(current.name, cty.MapVal(current.hclMap))
 					}
 
+				} else if s := current.attributeSchema(); s != nil && !current.inlined {
+					// objects typed as attributes in the Terraform schema, rather than blocks
+					parent.appendObject(current.name, current.objectValue(), s.MaxItems != 1)
+
 				} else if !current.inlined {
-					parent.AppendBlock(current.hcl)
+					parent.AppendBlock(current.block)
 				}
 			}
 		}
@@ -276,8 +281,9 @@ func (w *ObjectWalker) Struct(v reflect.Value) error {
 		// Create the top level HCL block
 		// e.g.
 		//   resource "kubernetes_pod" "name" { }
-		topLevelBlock := hclwrite.NewBlock("resource", []string{w.ResourceType(), w.ResourceName()})
-		w.openBlock(w.ResourceType(), k8sutils.TypeMeta(w.RuntimeObject).Kind, topLevelBlock)
+		topLevelBlock := tfconfig.NewBlock("resource", []string{w.ResourceType(), w.ResourceName()})
+		b := w.openBlock(w.ResourceType(), k8sutils.TypeMeta(w.RuntimeObject).Kind, topLevelBlock)
+		b.value = v
 		w.isTopLevel = false
 
 	} else {
@@ -295,7 +301,8 @@ func (w *ObjectWalker) Struct(v reflect.Value) error {
 		// generate a block name
 		blockName := tfkschema.ToTerraformSubBlockName(field, w.currentBlock.FullSchemaName())
 		w.debugf("creating block [%s] for field [%s]", blockName, field.Name)
-		b := w.openBlock(blockName, field.Name, hclwrite.NewBlock(blockName, nil))
+		b := w.openBlock(blockName, field.Name, tfconfig.NewBlock(blockName, nil))
+		b.value = v
 
 		// Skip some Kubernetes complex types that should be treated as primitives.
 		// Do this after opening the block above because reflectwalk will
@@ -308,7 +315,11 @@ func (w *ObjectWalker) Struct(v reflect.Value) error {
 			ios := v.Interface().(intstr.IntOrString)
 			if ios.IntVal > 0 || ios.StrVal != "" {
 				b.hasValue = false
-				b.parent.SetAttributeValue(blockName, w.convertCtyValue(v.Interface()))
+				val := w.convertCtyValue(v.Interface())
+				if w.stripDefault(b.parent, blockName, v, val) {
+					return reflectwalk.SkipEntry
+				}
+				b.parent.SetAttributeValue(blockName, val)
 				b.parent.hasValue = true
 			}
 			return reflectwalk.SkipEntry
@@ -351,11 +362,15 @@ func (w *ObjectWalker) Primitive(v reflect.Value) error {
 		w.debug(fmt.Sprintf("Primitive: %s = %v (%T)", w.field().Name, v.Interface(), v.Interface()))
 
 		if !IsZero(v) || tfkschema.IncludedOnZero(w.field().Name) {
+			name := tfkschema.ToTerraformAttributeName(w.field(), w.currentBlock.FullSchemaName())
+			val := w.convertCtyValue(v.Interface())
+
+			if w.stripDefault(w.currentBlock, name, v, val) {
+				return nil
+			}
+
 			w.currentBlock.hasValue = true
-			w.currentBlock.SetAttributeValue(
-				tfkschema.ToTerraformAttributeName(w.field(), w.currentBlock.FullSchemaName()),
-				w.convertCtyValue(v.Interface()),
-			)
+			w.currentBlock.SetAttributeValue(name, val)
 		}
 	}
 	return nil
@@ -375,8 +390,7 @@ func (w *ObjectWalker) Map(m reflect.Value) error {
 		blockName = "data"
 	}
 
-	hcl := hclwrite.NewBlock(blockName, nil)
-	b := w.openBlock(blockName, w.field().Name, hcl)
+	b := w.openBlock(blockName, w.field().Name, tfconfig.NewBlock(blockName, nil))
 
 	// If this field is also typed as Map in the Terraform schema, flag the block appropriately.
 	// This will impact whether the block is rendered as a map or HCL sub-block.
@@ -470,7 +484,7 @@ func (w *ObjectWalker) Slice(v reflect.Value) error {
 
 			// primitive type
 			w.currentBlock.hasValue = true
-			w.currentBlock.hcl.Body().SetAttributeValue(
+			w.currentBlock.block.Body.SetAttributeValue(
 				tfkschema.ToTerraformAttributeName(w.field(), w.currentBlock.FullSchemaName()),
 				val,
 			)
@@ -606,6 +620,29 @@ func (w *ObjectWalker) warn() *zerolog.Event {
 	return w.decorateEvent(log.Warn())
 }
 
+// skip records a field that was excluded from the generated HCL
+func (w *ObjectWalker) skip(fieldPath, schemaPath string) {
+	w.skippedFields = append(w.skippedFields, SkippedField{
+		FieldPath:  fieldPath,
+		SchemaPath: schemaPath,
+	})
+}
+
+// SkippedFields returns the attributes and blocks that were excluded from the generated HCL
+func (w *ObjectWalker) SkippedFields() []SkippedField {
+	return w.skippedFields
+}
+
+// DefaultFields returns the attributes that were excluded from the generated HCL by StripDefaults
+func (w *ObjectWalker) DefaultFields() []SkippedField {
+	return w.defaultFields
+}
+
+// WarnCount returns the number of warnings raised while walking the object
+func (w *ObjectWalker) WarnCount() int {
+	return w.warnCount
+}
+
 func (w *ObjectWalker) decorateEvent(e *zerolog.Event) *zerolog.Event {
 	e.
 		Str("name", w.ResourceName()).
@@ -1,4 +1,4 @@
-package main
+package converter
 
 import (
 	"os"
@@ -178,15 +178,15 @@ func TestWriteObject(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			// Generate HCL from test data
-			obj := testutils.TestParseYAML(t, testLoadFile(t, "test-fixtures", tt.name+".yaml"))
+			obj := testutils.TestParseYAML(t, testLoadFile(t, "../../test-fixtures", tt.name+".yaml"))
 			hclFile := hclwrite.NewEmptyFile()
-			warnCount, err := WriteObject(obj, hclFile.Body())
+			warnCount, err := New(Options{}).WriteObject(obj, hclFile.Body())
 			if err != nil {
 				t.Fatal(err)
 			}
 
 			// Read our golden file (or optionally write if env var is set)
-			goldenFile := filepath.Join("test-fixtures", tt.name+".tf.golden")
+			goldenFile := filepath.Join("../../test-fixtures", tt.name+".tf.golden")
 			if update {
 				os.WriteFile(goldenFile, hclFile.Bytes(), 0644)
 			}
@@ -0,0 +1,111 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/zclconf/go-cty/cty"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// defaultNamespace is the namespace Kubernetes assigns to namespaced objects that don't specify one
+const defaultNamespace = "default"
+
+// ResourceAddress returns the address of the Terraform resource generated for obj
+// e.g. kubernetes_deployment.backend_api
+func ResourceAddress(obj runtime.Object) string {
+	if tfkschema.IsKubernetesKindSupported(obj) {
+		return tfkschema.ToTerraformResourceType(obj) + "." + tfkschema.ToTerraformResourceName(obj)
+	}
+
+	return manifestResourceType + "." + ManifestResourceName(obj)
+}
+
+// ImportID returns the resource ID the Terraform provider expects when importing obj.
+// Namespaced resources use "<namespace>/<name>", while cluster scoped resources
+// (those without a metadata.namespace attribute in the provider schema) use "<name>".
+// kubernetes_manifest resources use "apiVersion=<apiVersion>,kind=<kind>[,namespace=<namespace>],name=<name>".
+// Namespaced resources without a namespace default to "default", but kubernetes_manifest resources don't: whether
+// their kind is namespaced is not known, so the namespace is only included when the object sets one.
+func ImportID(obj runtime.Object) string {
+	meta := k8sutils.ObjectMeta(obj)
+
+	if !tfkschema.IsKubernetesKindSupported(obj) {
+		tmeta := k8sutils.TypeMeta(obj)
+		parts := []string{
+			"apiVersion=" + tmeta.APIVersion,
+			"kind=" + tmeta.Kind,
+		}
+		if meta.Namespace != "" {
+			parts = append(parts, "namespace="+meta.Namespace)
+		}
+		parts = append(parts, "name="+meta.Name)
+
+		return strings.Join(parts, ",")
+	}
+
+	if !tfkschema.IsAttributeSupported(tfkschema.ToTerraformResourceType(obj) + ".metadata.namespace") {
+		// cluster scoped resource
+		return meta.Name
+	}
+
+	ns := meta.Namespace
+	if ns == "" {
+		ns = defaultNamespace
+	}
+
+	return ns + "/" + meta.Name
+}
+
+// WriteImportBlock appends a Terraform 1.5+ import block for obj to dst
+// e.g.
+//
+//	import {
+//	  to = kubernetes_deployment.backend_api
+//	  id = "default/backend-api"
+//	}
+func WriteImportBlock(obj runtime.Object, dst *tfconfig.Body) error {
+	to, diags := hclsyntax.ParseTraversalAbs([]byte(ResourceAddress(obj)), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return fmt.Errorf("could not parse resource address: %s", diags.Error())
+	}
+
+	block := tfconfig.NewBlock("import", nil)
+	block.Body.SetAttribute("to", tfconfig.Expression{Traversal: to, Static: true})
+	block.Body.SetAttributeValue("id", cty.StringVal(ImportID(obj)))
+	dst.AppendBlock(block)
+
+	return nil
+}
+
+// WriteImportCommand writes the `terraform import` shell command for obj to w
+func WriteImportCommand(obj runtime.Object, w io.Writer) error {
+	return writeImportCommand(ResourceAddress(obj), ImportID(obj), w)
+}
+
+// WriteImportCommand writes the `terraform import` shell command for the resource generated for r to w.
+// Unlike the WriteImportCommand func, the address includes the for_each key of collapsed resources.
+func (r *Result) WriteImportCommand(w io.Writer) error {
+	return writeImportCommand(r.Address(), ImportID(r.Object), w)
+}
+
+func writeImportCommand(address, id string, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "terraform import %s %s\n", shellQuote(address), shellQuote(id))
+	return err
+}
+
+// WriteImportScriptHeader writes the preamble of the import shell script to w
+func WriteImportScriptHeader(w io.Writer) error {
+	_, err := fmt.Fprint(w, "#!/bin/sh\nset -e\n\n")
+	return err
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
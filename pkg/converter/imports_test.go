@@ -0,0 +1,105 @@
+package converter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/sl1pm4t/k2tf/pkg/testutils"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportID(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  []string
+		wantAddr string
+		wantID   string
+	}{
+		{
+			"namespaced",
+			[]string{"../../test-fixtures", "configMap.yaml"},
+			"kubernetes_config_map.foo_config_map",
+			"bar/foo-config-map",
+		},
+		{
+			"namespaced/default",
+			[]string{"../../test-fixtures", "service.yaml"},
+			"kubernetes_service.nginx",
+			"default/nginx",
+		},
+		{
+			"cluster_scoped",
+			[]string{"../../test-fixtures", "clusterRole.yaml"},
+			"kubernetes_cluster_role.monitoring",
+			"monitoring",
+		},
+		{
+			"namespace",
+			[]string{"../../test-fixtures", "namespace.yaml"},
+			"kubernetes_namespace.cert_manager",
+			"cert-manager",
+		},
+		{
+			"manifest",
+			[]string{"../../test-fixtures", "manifest", "cronTab.yaml"},
+			"kubernetes_manifest.cron_tab_my_new_cron_object",
+			"apiVersion=stable.example.com/v1,kind=CronTab,namespace=default,name=my-new-cron-object",
+		},
+		{
+			"manifest/cluster_scoped",
+			[]string{"../../test-fixtures", "manifest", "customResourceDefinition.yaml"},
+			"kubernetes_manifest.custom_resource_definition_crontabs_stable_example_com",
+			"apiVersion=apiextensions.k8s.io/v1,kind=CustomResourceDefinition,name=crontabs.stable.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := testutils.TestParseYAML(t, testLoadFile(t, tt.fixture...))
+
+			assert.Equal(t, tt.wantAddr, ResourceAddress(obj))
+			assert.Equal(t, tt.wantID, ImportID(obj))
+		})
+	}
+}
+
+func TestImportID_manifestWithoutNamespace(t *testing.T) {
+	obj := testutils.TestParseYAML(t, `apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: my-new-cron-object
+spec:
+  cronSpec: "* * * * */5"
+`)
+
+	// unlike typed resources, the scope of the kind is not known, so no namespace is assumed
+	assert.Equal(t, "apiVersion=stable.example.com/v1,kind=CronTab,name=my-new-cron-object", ImportID(obj))
+}
+
+func TestWriteImportBlock(t *testing.T) {
+	obj := testutils.TestParseYAML(t, testLoadFile(t, "../../test-fixtures", "configMap.yaml"))
+
+	body := tfconfig.NewBody()
+	if err := WriteImportBlock(obj, body); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `import {
+  to = kubernetes_config_map.foo_config_map
+  id = "bar/foo-config-map"
+}
+`
+	assert.Equal(t, expected, string(hclwrite.Format(body.HCL())))
+}
+
+func TestWriteImportCommand(t *testing.T) {
+	obj := testutils.TestParseYAML(t, testLoadFile(t, "../../test-fixtures", "configMap.yaml"))
+
+	var buf bytes.Buffer
+	if err := WriteImportCommand(obj, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "terraform import 'kubernetes_config_map.foo_config_map' 'bar/foo-config-map'\n", buf.String())
+}
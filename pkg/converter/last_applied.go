@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// LastAppliedConfigAnnotation is the annotation kubectl apply stores the applied configuration in
+const LastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// LastAppliedConfiguration decodes the configuration stored in the last-applied-configuration annotation of obj.
+// nil is returned when obj has no such annotation.
+// The namespace of obj is used when the applied configuration has none, as kubectl apply may omit it.
+func LastAppliedConfiguration(obj runtime.Object) (runtime.Object, error) {
+	om := k8sutils.ObjectMeta(obj)
+	content, ok := om.Annotations[LastAppliedConfigAnnotation]
+	if !ok || content == "" {
+		return nil, nil
+	}
+
+	var applied runtime.Object
+	if k8sutils.IsUnstructured(obj) {
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON([]byte(content)); err != nil {
+			return nil, fmt.Errorf("could not decode %s annotation: %w", LastAppliedConfigAnnotation, err)
+		}
+		applied = u
+
+	} else {
+		var err error
+		applied, err = k8sparser.ParseJSON([]byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("could not decode %s annotation: %w", LastAppliedConfigAnnotation, err)
+		}
+	}
+
+	accessor, err := meta.Accessor(applied)
+	if err != nil {
+		return nil, err
+	}
+	if accessor.GetNamespace() == "" {
+		accessor.SetNamespace(om.Namespace)
+	}
+
+	return applied, nil
+}
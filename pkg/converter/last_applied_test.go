@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConverter_LastApplied(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "last_applied", "deployment.yaml"})
+
+	r, err := New(Options{LastApplied: true}).ConvertObject(objs[0])
+	require.NoError(t, err)
+
+	assert.True(t, r.LastApplied)
+	assert.Same(t, objs[0], r.Input)
+	assert.NotSame(t, r.Input, r.Object)
+	assert.Equal(t, "shop", k8sutils.ObjectMeta(r.Object).Namespace, "namespace of the live object should be used")
+
+	// Read our golden file (or optionally write if env var is set)
+	goldenFile := filepath.Join("../../test-fixtures", "last_applied", "deployment.tf.golden")
+	if update {
+		os.WriteFile(goldenFile, r.HCL, 0644)
+	}
+	expected := testLoadFile(t, goldenFile)
+
+	assert.Equal(t, expected, string(r.HCL), "should be equal")
+}
+
+func TestConverter_LastApplied_fallback(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantWarnings int
+	}{
+		{
+			"no annotation",
+			`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  key: value
+`,
+			0,
+		},
+		{
+			"invalid annotation",
+			`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: "{not json"
+data:
+  key: value
+`,
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := k8sparser.ParseYAML(strings.NewReader(tt.input))
+			require.NoError(t, err)
+
+			r, err := New(Options{LastApplied: true}).ConvertObject(objs[0])
+			require.NoError(t, err)
+
+			assert.False(t, r.LastApplied)
+			assert.Same(t, r.Input, r.Object)
+			assert.Len(t, r.Warnings, tt.wantWarnings)
+			assert.Contains(t, string(r.HCL), `key = "value"`)
+		})
+	}
+}
+
+func TestLastAppliedConfiguration_unstructured(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name":      "gizmo",
+			"namespace": "shop",
+			"annotations": map[string]interface{}{
+				LastAppliedConfigAnnotation: `{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"gizmo"},"spec":{"size":1}}`,
+			},
+		},
+		"spec": map[string]interface{}{"size": int64(3)},
+	}}
+
+	applied, err := LastAppliedConfiguration(u)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "gizmo", "namespace": "shop"},
+		"spec":       map[string]interface{}{"size": int64(1)},
+	}, applied.(*unstructured.Unstructured).Object)
+}
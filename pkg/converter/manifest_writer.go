@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/zclconf/go-cty/cty"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// manifestResourceType is the generic Terraform resource that can hold any Kubernetes API Object
+const manifestResourceType = "kubernetes_manifest"
+
+// ignoredManifestMetadata lists the server populated metadata fields that are
+// removed from the rendered manifest.
+var ignoredManifestMetadata = []string{
+	"creationTimestamp",
+	"deletionTimestamp",
+	"generation",
+	"managedFields",
+	"ownerReferences",
+	"resourceVersion",
+	"selfLink",
+	"uid",
+}
+
+// WriteManifest converts a Kubernetes runtime.Object to a `kubernetes_manifest` resource, appended to dst.
+// It's used for objects that have no dedicated resource in the Terraform provider, such as Custom Resources.
+func WriteManifest(obj runtime.Object, dst *tfconfig.Body) error {
+	if obj == nil {
+		return fmt.Errorf("obj cannot be nil")
+	}
+
+	var content map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		content = runtime.DeepCopyJSON(u.UnstructuredContent())
+	} else {
+		var err error
+		content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return fmt.Errorf("could not convert object to unstructured content: %w", err)
+		}
+	}
+
+	delete(content, "status")
+	if meta, ok := content["metadata"].(map[string]interface{}); ok {
+		for _, f := range ignoredManifestMetadata {
+			delete(meta, f)
+		}
+	}
+
+	manifest, err := toManifestValue(content)
+	if err != nil {
+		return err
+	}
+
+	block := tfconfig.NewBlock("resource", []string{manifestResourceType, ManifestResourceName(obj)})
+	block.Body.SetAttributeValue("manifest", manifest)
+	dst.AppendBlock(block)
+
+	return nil
+}
+
+// ManifestResourceName returns the Terraform resource name used for a `kubernetes_manifest` resource.
+// All manifests share the same resource type, so the object kind is prefixed to avoid name collisions.
+func ManifestResourceName(obj runtime.Object) string {
+	kind := tfkschema.NormalizeTerraformName(k8sutils.TypeMeta(obj).Kind, false, "")
+	return kind + "_" + tfkschema.ToTerraformResourceName(obj)
+}
+
+// toManifestValue converts unstructured object content to the equivalent cty value, so it
+// can be rendered as a HCL object expression.
+// Null values are dropped.
+func toManifestValue(v interface{}) (cty.Value, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 {
+			return cty.EmptyObjectVal, nil
+		}
+
+		attrs := map[string]cty.Value{}
+		for k, e := range val {
+			if e == nil {
+				continue
+			}
+			av, err := toManifestValue(e)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[k] = av
+		}
+		if len(attrs) == 0 {
+			return cty.EmptyObjectVal, nil
+		}
+		return cty.ObjectVal(attrs), nil
+
+	case []interface{}:
+		if len(val) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+
+		elems := make([]cty.Value, 0, len(val))
+		for _, e := range val {
+			if e == nil {
+				continue
+			}
+			ev, err := toManifestValue(e)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems = append(elems, ev)
+		}
+		if len(elems) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		return cty.TupleVal(elems), nil
+
+	case string:
+		return cty.StringVal(val), nil
+	case bool:
+		return cty.BoolVal(val), nil
+	case int64:
+		return cty.NumberIntVal(val), nil
+	case int:
+		return cty.NumberIntVal(int64(val)), nil
+	case float64:
+		return cty.NumberFloatVal(val), nil
+	}
+
+	return cty.NilVal, fmt.Errorf("unhandled manifest value type: %T", v)
+}
@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/sl1pm4t/k2tf/pkg/testutils"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteManifest(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceName string
+	}{
+		{
+			"cronTab",
+			"cron_tab_my_new_cron_object",
+		},
+		{
+			"customResourceDefinition",
+			"custom_resource_definition_crontabs_stable_example_com",
+		},
+		{
+			"replicaSet",
+			"replica_set_frontend",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Generate HCL from test data
+			obj := testutils.TestParseYAML(t, testLoadFile(t, "../../test-fixtures", "manifest", tt.name+".yaml"))
+			body := tfconfig.NewBody()
+			err := WriteManifest(obj, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hclFile := hclwrite.NewEmptyFile()
+			body.WriteHCL(hclFile.Body())
+
+			// Read our golden file (or optionally write if env var is set)
+			goldenFile := filepath.Join("../../test-fixtures", "manifest", tt.name+".tf.golden")
+			if update {
+				os.WriteFile(goldenFile, hclFile.Bytes(), 0644)
+			}
+			expected := testLoadFile(t, goldenFile)
+
+			// Validate configs are equal
+			assert.Equal(t, expected, string(hclFile.Bytes()), "should be equal")
+
+			assert.Equal(t, tt.resourceName, ManifestResourceName(obj))
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DefaultParameterPaths are the attribute paths whose values are lifted by Options.Parameterize, when the same
+// value is used by more than one resource. Paths are matched as glob patterns, where "*" matches a single
+// attribute or block name and "**" matches any number of them.
+var DefaultParameterPaths = []string{
+	"**.namespace",
+	"**.labels",
+	"**.match_labels",
+	"spec.selector",
+	"spec.replicas",
+	"**.container.image",
+	"**.init_container.image",
+	"**.resources.limits",
+	"**.resources.requests",
+}
+
+// Parameter is a literal value that's lifted out of the generated resources into a local value, or a variable
+// with a default value, and referenced by the resources instead.
+type Parameter struct {
+	// Name of the local value or variable
+	Name string `json:"name"`
+	// Value is the literal value that was lifted
+	Value cty.Value `json:"-"`
+	// Variable is true when the value is lifted into a variable, rather than a local value
+	Variable bool `json:"variable"`
+}
+
+// Reference returns the expression that references p, e.g. local.namespace or var.image
+func (p *Parameter) Reference() hcl.Traversal {
+	root := "local"
+	if p.Variable {
+		root = "var"
+	}
+	return hcl.Traversal{
+		hcl.TraverseRoot{Name: root},
+		hcl.TraverseAttr{Name: p.Name},
+	}
+}
+
+// WriteParameters appends the definition of params to dst: a locals block holding the local values, and a
+// variable block, with a type and default value, for each variable.
+func WriteParameters(params []*Parameter, dst *tfconfig.Body) {
+	locals := tfconfig.NewBlock("locals", nil)
+	for _, p := range params {
+		if !p.Variable {
+			locals.Body.SetAttributeValue(p.Name, p.Value)
+		}
+	}
+	if len(locals.Body.Attributes()) > 0 {
+		dst.AppendBlock(locals)
+	}
+
+	for _, p := range params {
+		if !p.Variable {
+			continue
+		}
+		block := tfconfig.NewBlock("variable", []string{p.Name})
+		block.Body.SetAttribute("type", tfconfig.Expression{
+			Traversal: hcl.Traversal{hcl.TraverseRoot{Name: p.Value.Type().FriendlyNameForConstraint()}},
+			Static:    true,
+		})
+		block.Body.SetAttributeValue("default", p.Value)
+		dst.AppendBlock(block)
+	}
+}
+
+// parameterLeaf is a literal value of a generated resource, whose path matches a parameter path
+type parameterLeaf struct {
+	leaf
+	result *Result
+	// configured is true when the path matches Options.ParameterPaths, rather than DefaultParameterPaths
+	configured bool
+}
+
+// parameterGroup holds the leaves with the same name and value, that are lifted into a single Parameter
+type parameterGroup struct {
+	base   string
+	leaves []parameterLeaf
+	param  *Parameter
+}
+
+// parameterize lifts literal values of the generated resources into local values or variables.
+//
+// Values at Options.ParameterPaths are always lifted. With Options.Parameterize, values at
+// DefaultParameterPaths are lifted when the same value is used by more than one resource. Values are grouped
+// by the last element of their path and their value, e.g. the app label of a Deployment and its Service, so
+// that selectors and labels keep matching. The definitions of the parameters are written above the resources
+// of the first Result that references them, and the parameters referenced by each resource are set on its
+// Result.
+func (c *Converter) parameterize(results []*Result) error {
+	configured, err := compilePaths(c.opts.ParameterPaths)
+	if err != nil {
+		return err
+	}
+	var defaults []glob.Glob
+	if c.opts.Parameterize {
+		if defaults, err = compilePaths(DefaultParameterPaths); err != nil {
+			return err
+		}
+	}
+
+	var groups []*parameterGroup
+	byKey := map[string]*parameterGroup{}
+	used := map[string]bool{}
+	for _, r := range results {
+		if r == nil || r.Skipped || r.Config == nil {
+			continue
+		}
+		definedNames(r.Config, used)
+
+		resource := resourceBlock(r)
+		if r.ResourceType == manifestResourceType || resource == nil {
+			continue
+		}
+
+		var shape strings.Builder
+		for _, l := range walkLeaves(resource.Body, nil, &shape) {
+			if !isParameterValue(l.expr) {
+				continue
+			}
+			pl := parameterLeaf{leaf: l, result: r, configured: matchPath(configured, l.path)}
+			if !pl.configured && !matchPath(defaults, l.path) {
+				continue
+			}
+
+			base := identifier(l.path[len(l.path)-1])
+			key := base + "=" + string(hclwrite.TokensForValue(l.expr.Value).Bytes())
+			g, ok := byKey[key]
+			if !ok {
+				g = &parameterGroup{base: base}
+				byKey[key] = g
+				groups = append(groups, g)
+			}
+			g.leaves = append(g.leaves, pl)
+		}
+	}
+
+	var lifted []*parameterGroup
+	for _, g := range groups {
+		if g.lift() {
+			lifted = append(lifted, g)
+		}
+	}
+	nameParameters(lifted, used, c.opts.ParameterVariables)
+
+	var params []*Parameter
+	changed := map[*Result]bool{}
+	for _, g := range lifted {
+		params = append(params, g.param)
+
+		log.Debug().
+			Str("name", g.param.Name).
+			Msgf("lifting value used %d times", len(g.leaves))
+
+		ref := tfconfig.TraversalExpr(g.param.Reference())
+		for _, l := range g.leaves {
+			l.set(ref)
+			changed[l.result] = true
+			if !hasParameter(l.result, g.param) {
+				l.result.Parameters = append(l.result.Parameters, g.param)
+			}
+		}
+	}
+	first := true
+	for _, r := range results {
+		if !changed[r] {
+			continue
+		}
+		if first {
+			body := tfconfig.NewBody()
+			WriteParameters(params, body)
+			for _, b := range r.Config.Blocks() {
+				body.AppendBlock(b)
+			}
+			r.Config = body
+			first = false
+		}
+		r.HCL = c.format(r.Config.HCL())
+	}
+
+	return nil
+}
+
+// lift returns true when the value of g is lifted: when it's at a configured path, or used by more than one
+// resource
+func (g *parameterGroup) lift() bool {
+	resources := map[*Result]bool{}
+	for _, l := range g.leaves {
+		if l.configured {
+			return true
+		}
+		resources[l.result] = true
+	}
+	return len(resources) > 1
+}
+
+// nameParameters creates the Parameter of each group. Groups are named after the map attribute and key holding
+// their values (e.g. limits_cpu), or the last element of their path (e.g. namespace). When several groups share
+// a name, it's qualified by the resource name of the first resource using the value (e.g. image_backend).
+// Names in used are not reused.
+func nameParameters(groups []*parameterGroup, used map[string]bool, variables bool) {
+	names := make([]string, len(groups))
+	count := map[string]int{}
+	for i, g := range groups {
+		names[i] = g.base
+		if parent := g.parent(); parent != "" {
+			names[i] = parent + "_" + g.base
+		}
+		count[names[i]]++
+	}
+
+	for i, g := range groups {
+		candidates := []string{names[i] + "_" + g.leaves[0].result.ResourceName}
+		if count[names[i]] == 1 {
+			candidates = append([]string{names[i]}, candidates...)
+		}
+
+		name := ""
+		for _, c := range candidates {
+			if !used[c] {
+				name = c
+				break
+			}
+		}
+		for n := 2; name == ""; n++ {
+			if c := fmt.Sprintf("%s_%d", names[i], n); !used[c] {
+				name = c
+			}
+		}
+		used[name] = true
+
+		g.param = &Parameter{
+			Name:     name,
+			Value:    g.leaves[0].expr.Value,
+			Variable: variables,
+		}
+	}
+}
+
+// parent returns the name of the map attribute holding the values of g, if they're all held by map
+// attributes of the same name
+func (g *parameterGroup) parent() string {
+	parent := ""
+	for _, l := range g.leaves {
+		if !l.mapKey {
+			return ""
+		}
+		p := identifier(l.path[len(l.path)-2])
+		if parent != "" && p != parent {
+			return ""
+		}
+		parent = p
+	}
+	return parent
+}
+
+// isParameterValue returns true when expr is a literal primitive value that can be lifted
+func isParameterValue(expr tfconfig.Expression) bool {
+	v := expr.Value
+	if expr.Traversal != nil || expr.Object != nil || v == cty.NilVal || v.IsNull() || !v.IsKnown() {
+		return false
+	}
+	if !v.Type().IsPrimitiveType() {
+		return false
+	}
+	return v.Type() != cty.String || v.AsString() != ""
+}
+
+// definedNames records the names of the variables and local values defined in body
+func definedNames(body *tfconfig.Body, names map[string]bool) {
+	for _, b := range body.Blocks() {
+		switch b.Type {
+		case "variable":
+			names[b.Labels[0]] = true
+		case "locals":
+			for _, attr := range b.Body.Attributes() {
+				names[attr.Name] = true
+			}
+		}
+	}
+}
+
+func hasParameter(r *Result, p *Parameter) bool {
+	for _, e := range r.Parameters {
+		if e == p {
+			return true
+		}
+	}
+	return false
+}
+
+func compilePaths(patterns []string) ([]glob.Glob, error) {
+	var globs []glob.Glob
+	for _, p := range patterns {
+		g, err := glob.Compile(p, '.')
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter path %q: %w", p, err)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// matchPath returns true when path, or one of the blocks or map attributes holding it, matches one of globs
+func matchPath(globs []glob.Glob, path []string) bool {
+	for n := len(path); n > 0; n-- {
+		p := strings.Join(path[:n], ".")
+		for _, g := range globs {
+			if g.Match(p) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverter_Parameterize(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		wantParams [][]string
+	}{
+		{
+			"shop",
+			Options{Parameterize: true},
+			[][]string{
+				{"namespace", "app", "labels_team", "replicas", "limits_cpu", "requests_cpu", "requests_memory"},
+				{"namespace", "app", "labels_team"},
+				{"namespace", "labels_team", "replicas", "limits_cpu", "requests_cpu", "requests_memory"},
+			},
+		},
+		{
+			"shop_variables",
+			Options{ParameterPaths: []string{"**.container.image"}, ParameterVariables: true},
+			[][]string{
+				{"image_frontend"},
+				nil,
+				{"image_backend"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := testParseFixtures(t, []string{"../../test-fixtures", "parameters", "shop.yaml"})
+
+			results, err := New(tt.opts).Convert(objs)
+			require.NoError(t, err)
+
+			var params [][]string
+			var hcl []string
+			for _, r := range results {
+				var names []string
+				for _, p := range r.Parameters {
+					names = append(names, p.Name)
+					assert.Equal(t, tt.opts.ParameterVariables, p.Variable)
+				}
+				params = append(params, names)
+				hcl = append(hcl, string(r.HCL))
+			}
+			assert.Equal(t, tt.wantParams, params)
+
+			// Read our golden file (or optionally write if env var is set)
+			goldenFile := filepath.Join("../../test-fixtures", "parameters", tt.name+".tf.golden")
+			if update {
+				os.WriteFile(goldenFile, []byte(strings.Join(hcl, "\n")), 0644)
+			}
+			expected := testLoadFile(t, goldenFile)
+
+			assert.Equal(t, expected, strings.Join(hcl, "\n"), "should be equal")
+		})
+	}
+}
+
+func TestConverter_Parameterize_invalidPath(t *testing.T) {
+	objs := testParseFixtures(t, []string{"../../test-fixtures", "parameters", "shop.yaml"})
+
+	_, err := New(Options{ParameterPaths: []string{"spec.[replicas"}}).Convert(objs)
+	assert.ErrorContains(t, err, `invalid parameter path "spec.[replicas"`)
+}
+
+func Test_matchPath(t *testing.T) {
+	globs, err := compilePaths(DefaultParameterPaths)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path []string
+		want bool
+	}{
+		{[]string{"metadata", "namespace"}, true},
+		{[]string{"metadata", "labels", "app.kubernetes.io/name"}, true},
+		{[]string{"spec", "template", "spec", "container", "image"}, true},
+		{[]string{"spec", "template", "spec", "container", "resources", "limits", "cpu"}, true},
+		{[]string{"spec", "template", "spec", "container", "name"}, false},
+		{[]string{"spec", "template", "spec", "replicas"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.path, "."), func(t *testing.T) {
+			assert.Equal(t, tt.want, matchPath(globs, tt.path))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package converter
+
+import (
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// WriteRequiredProviders appends a terraform block to dst, requiring the Terraform Kubernetes provider
+// e.g.
+//
+//	terraform {
+//	  required_providers {
+//	    kubernetes = {
+//	      source  = "hashicorp/kubernetes"
+//	      version = "~> 2.19"
+//	    }
+//	  }
+//	}
+//
+// The version constraint is omitted when it's empty.
+func WriteRequiredProviders(constraint string, dst *tfconfig.Body) {
+	provider := map[string]cty.Value{
+		"source": cty.StringVal(tfkschema.ProviderSource),
+	}
+	if constraint != "" {
+		provider["version"] = cty.StringVal(constraint)
+	}
+
+	terraform := tfconfig.NewBlock("terraform", nil)
+	required := terraform.Body.AppendBlock(tfconfig.NewBlock("required_providers", nil))
+	required.Body.SetAttributeValue("kubernetes", cty.ObjectVal(provider))
+	dst.AppendBlock(terraform)
+}
+
+// WriteProviderConfig appends a provider block for the Terraform Kubernetes provider to dst, configured with
+// the given kubeconfig file and context. The context is omitted when it's empty.
+func WriteProviderConfig(configPath, configContext string, dst *tfconfig.Body) {
+	provider := tfconfig.NewBlock("provider", []string{"kubernetes"})
+	provider.Body.SetAttributeValue("config_path", cty.StringVal(configPath))
+	if configContext != "" {
+		provider.Body.SetAttributeValue("config_context", cty.StringVal(configContext))
+	}
+	dst.AppendBlock(provider)
+}
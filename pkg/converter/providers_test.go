@@ -0,0 +1,88 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRequiredProviders(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		want       string
+	}{
+		{
+			"version",
+			"~> 2.19",
+			`terraform {
+  required_providers {
+    kubernetes = {
+      source  = "hashicorp/kubernetes"
+      version = "~> 2.19"
+    }
+  }
+}
+`,
+		},
+		{
+			"no version",
+			"",
+			`terraform {
+  required_providers {
+    kubernetes = {
+      source = "hashicorp/kubernetes"
+    }
+  }
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tfconfig.NewBody()
+			WriteRequiredProviders(tt.constraint, body)
+
+			assert.Equal(t, tt.want, string(hclwrite.Format(body.HCL())))
+		})
+	}
+}
+
+func TestWriteProviderConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		configPath    string
+		configContext string
+		want          string
+	}{
+		{
+			"context",
+			"~/.kube/config",
+			"prod",
+			`provider "kubernetes" {
+  config_path    = "~/.kube/config"
+  config_context = "prod"
+}
+`,
+		},
+		{
+			"no context",
+			"/etc/kubeconfig",
+			"",
+			`provider "kubernetes" {
+  config_path = "/etc/kubeconfig"
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tfconfig.NewBody()
+			WriteProviderConfig(tt.configPath, tt.configContext, body)
+
+			assert.Equal(t, tt.want, string(hclwrite.Format(body.HCL())))
+		})
+	}
+}
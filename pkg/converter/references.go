@@ -0,0 +1,176 @@
+package converter
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/zclconf/go-cty/cty"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// referenceRule describes an attribute of a HCL block that holds the name of another Kubernetes object
+type referenceRule struct {
+	block string
+	attr  string
+	kind  string
+}
+
+var referenceRules = []referenceRule{
+	{"metadata", "namespace", "Namespace"},
+	{"spec", "service_account_name", "ServiceAccount"},
+	{"config_map", "name", "ConfigMap"},
+	{"config_map_ref", "name", "ConfigMap"},
+	{"config_map_key_ref", "name", "ConfigMap"},
+	{"secret", "secret_name", "Secret"},
+	{"secret", "name", "Secret"},
+	{"secret_ref", "name", "Secret"},
+	{"secret_key_ref", "name", "Secret"},
+	{"image_pull_secrets", "name", "Secret"},
+	{"persistent_volume_claim", "claim_name", "PersistentVolumeClaim"},
+}
+
+// clusterScopedReferenceKinds are the referenceable kinds that are looked up without a namespace
+var clusterScopedReferenceKinds = map[string]bool{
+	"Namespace":   true,
+	"ClusterRole": true,
+}
+
+type referenceKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// ReferenceIndex records the Terraform resource address of every object in a conversion batch,
+// so literal object names in the generated HCL can be replaced with references to the
+// matching Terraform resource.
+// e.g.
+//
+//	namespace = "monitoring"
+//
+// becomes
+//
+//	namespace = kubernetes_namespace.monitoring.metadata[0].name
+type ReferenceIndex struct {
+	resources map[referenceKey]string
+}
+
+// NewReferenceIndex builds a ReferenceIndex from all objects in the conversion batch
+func NewReferenceIndex(objs []runtime.Object) *ReferenceIndex {
+	r := &ReferenceIndex{
+		resources: map[referenceKey]string{},
+	}
+
+	for _, obj := range objs {
+		if !tfkschema.IsKubernetesKindSupported(obj) {
+			continue
+		}
+
+		kind := k8sutils.TypeMeta(obj).Kind
+		meta := k8sutils.ObjectMeta(obj)
+		key := r.key(kind, meta.Namespace, meta.Name)
+		r.resources[key] = tfkschema.ToTerraformResourceType(obj) + "." + tfkschema.ToTerraformResourceName(obj)
+	}
+
+	return r
+}
+
+func (r *ReferenceIndex) key(kind, namespace, name string) referenceKey {
+	if clusterScopedReferenceKinds[kind] {
+		namespace = ""
+	}
+	return referenceKey{kind: kind, namespace: namespace, name: name}
+}
+
+// lookup returns the traversal that references the named object, if it is part of the batch
+func (r *ReferenceIndex) lookup(kind, namespace, name string) (hcl.Traversal, bool) {
+	addr, ok := r.resources[r.key(kind, namespace, name)]
+	if !ok {
+		return nil, false
+	}
+
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(addr), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		log.Error().Str("address", addr).Msg("could not parse resource address")
+		return nil, false
+	}
+
+	return append(traversal,
+		hcl.TraverseAttr{Name: "metadata"},
+		hcl.TraverseIndex{Key: cty.NumberIntVal(0)},
+		hcl.TraverseAttr{Name: "name"},
+	), true
+}
+
+// Apply replaces literal object names in the config generated for obj with references
+// to other resources in the batch.
+func (r *ReferenceIndex) Apply(obj runtime.Object, dst *tfconfig.Body) {
+	namespace := k8sutils.ObjectMeta(obj).Namespace
+
+	for _, b := range dst.Blocks() {
+		if b.Type == "resource" {
+			r.applyBody(b.Body, namespace)
+		}
+	}
+}
+
+func (r *ReferenceIndex) applyBody(body *tfconfig.Body, namespace string) {
+	for _, b := range body.Blocks() {
+		switch b.Type {
+		case "subject":
+			r.applySubject(b.Body, namespace)
+
+		case "role_ref":
+			r.applyRoleRef(b.Body, namespace)
+
+		default:
+			for _, rule := range referenceRules {
+				if rule.block == b.Type {
+					r.replace(b.Body, rule.attr, rule.kind, namespace)
+				}
+			}
+		}
+
+		r.applyBody(b.Body, namespace)
+	}
+}
+
+// applySubject handles RoleBinding / ClusterRoleBinding subjects, which
+// specify the kind and namespace of the referenced object.
+func (r *ReferenceIndex) applySubject(body *tfconfig.Body, namespace string) {
+	kind, _ := body.StringValue("kind")
+	if ns, ok := body.StringValue("namespace"); ok {
+		namespace = ns
+	}
+
+	if kind == "ServiceAccount" {
+		r.replace(body, "name", kind, namespace)
+	}
+	r.replace(body, "namespace", "Namespace", "")
+}
+
+// applyRoleRef handles RoleBinding / ClusterRoleBinding role references
+func (r *ReferenceIndex) applyRoleRef(body *tfconfig.Body, namespace string) {
+	kind, _ := body.StringValue("kind")
+	if kind == "Role" || kind == "ClusterRole" {
+		r.replace(body, "name", kind, namespace)
+	}
+}
+
+func (r *ReferenceIndex) replace(body *tfconfig.Body, attr, kind, namespace string) {
+	name, ok := body.StringValue(attr)
+	if !ok {
+		return
+	}
+
+	if traversal, ok := r.lookup(kind, namespace, name); ok {
+		log.Debug().
+			Str("kind", kind).
+			Str("name", name).
+			Msgf("replacing [%s] with reference", attr)
+		body.SetAttributeTraversal(attr, traversal)
+	}
+}
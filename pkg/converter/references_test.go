@@ -0,0 +1,42 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferenceIndex_Apply(t *testing.T) {
+	objs, err := k8sparser.ParseYAML(strings.NewReader(testLoadFile(t, "../../test-fixtures", "references", "app.yaml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs := NewReferenceIndex(objs)
+
+	hclFile := hclwrite.NewEmptyFile()
+	for _, obj := range objs {
+		body := tfconfig.NewBody()
+		if _, err := New(Options{}).writeObject(obj, body); err != nil {
+			t.Fatal(err)
+		}
+		refs.Apply(obj, body)
+		body.WriteHCL(hclFile.Body())
+	}
+
+	// Read our golden file (or optionally write if env var is set)
+	goldenFile := filepath.Join("../../test-fixtures", "references", "app.tf.golden")
+	if update {
+		os.WriteFile(goldenFile, hclwrite.Format(hclFile.Bytes()), 0644)
+	}
+	expected := testLoadFile(t, goldenFile)
+
+	// Validate configs are equal
+	assert.Equal(t, expected, string(hclwrite.Format(hclFile.Bytes())), "should be equal")
+}
@@ -0,0 +1,144 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/zclconf/go-cty/cty"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SecretVariable describes a sensitive Terraform variable generated to hold the value of a Secret key
+type SecretVariable struct {
+	// Name of the Terraform variable
+	Name string `json:"name"`
+	// Secret is the namespace/name of the Secret the value was extracted from
+	Secret string `json:"secret"`
+	// Key is the key of the value in the Secret data
+	Key string `json:"key"`
+}
+
+// WriteSecretVariables replaces the values of the `data` attribute of the kubernetes_secret resource in dst
+// with references to sensitive variables, and appends a variable block for each key to dst.
+// The secret values are not written to the generated config. It returns the generated variables,
+// or nil if obj is not a Secret.
+// Variable names in used are not reused, and the generated names are added to it, so that variables generated for
+// a batch of Secrets are unique, e.g. for Secrets of the same name in different namespaces.
+func WriteSecretVariables(obj runtime.Object, dst *tfconfig.Body, used map[string]bool) ([]SecretVariable, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return nil, nil
+	}
+
+	keys := secretKeys(secret)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	resourceType := tfkschema.ToTerraformResourceType(obj)
+	resourceName := tfkschema.ToTerraformResourceName(obj)
+
+	var resource *tfconfig.Block
+	for _, b := range dst.Blocks() {
+		labels := b.Labels
+		if b.Type == "resource" && len(labels) == 2 && labels[0] == resourceType && labels[1] == resourceName {
+			resource = b
+			break
+		}
+	}
+	if resource == nil {
+		return nil, fmt.Errorf("could not find resource %s.%s", resourceType, resourceName)
+	}
+
+	ns := secret.Namespace
+	if ns == "" {
+		ns = defaultNamespace
+	}
+
+	var vars []SecretVariable
+	data := map[string]tfconfig.Expression{}
+	for _, k := range keys {
+		v := SecretVariable{
+			Name:   secretVariableName(resourceName, k, used),
+			Secret: ns + "/" + secret.Name,
+			Key:    k,
+		}
+		vars = append(vars, v)
+
+		ref, diags := hclsyntax.ParseTraversalAbs([]byte("var."+v.Name), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("could not parse variable reference: %s", diags.Error())
+		}
+
+		data[k] = tfconfig.TraversalExpr(ref)
+	}
+	resource.Body.SetAttribute("data", tfconfig.Expression{Object: data})
+
+	for _, v := range vars {
+		block := tfconfig.NewBlock("variable", []string{v.Name})
+		block.Body.SetAttribute("type", tfconfig.Expression{
+			Traversal: hcl.Traversal{hcl.TraverseRoot{Name: "string"}},
+			Static:    true,
+		})
+		block.Body.SetAttributeValue("sensitive", cty.True)
+		dst.AppendBlock(block)
+	}
+
+	return vars, nil
+}
+
+// WriteSecretVariablesTemplate writes a .tfvars template to w, with an empty placeholder for each variable
+func WriteSecretVariablesTemplate(vars []SecretVariable, w io.Writer) error {
+	f := hclwrite.NewEmptyFile()
+	for _, v := range vars {
+		f.Body().AppendUnstructuredTokens(hclwrite.Tokens{
+			{Type: hclsyntax.TokenComment, Bytes: []byte(fmt.Sprintf("# Secret %s, key %q\n", v.Secret, v.Key))},
+		})
+		f.Body().SetAttributeValue(v.Name, cty.StringVal(""))
+	}
+
+	_, err := w.Write(hclwrite.Format(f.Bytes()))
+	return err
+}
+
+// secretKeys returns the sorted keys of the Secret data and stringData.
+// The Terraform provider has no stringData attribute, so both are written to data.
+func secretKeys(secret *corev1.Secret) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for k := range secret.Data {
+		seen[k] = true
+		keys = append(keys, k)
+	}
+	for k := range secret.StringData {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// secretVariableName returns the variable name for a Secret key, e.g. secret_basic_auth_password.
+// A numeric suffix is added to names in used, e.g. secret_basic_auth_password_2 for the key password of
+// another basic-auth Secret, and the returned name is added to used.
+func secretVariableName(resourceName, key string, used map[string]bool) string {
+	base := resourceName + "_" + strings.Trim(tfkschema.NormalizeTerraformName(key, false, ""), "_")
+	name := base
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	if used != nil {
+		used[name] = true
+	}
+	return name
+}
@@ -0,0 +1,92 @@
+package converter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConverter_SecretVariables(t *testing.T) {
+	objs, err := k8sparser.ParseYAML(strings.NewReader(testLoadFile(t, "../../test-fixtures", "secrets", "secret.yaml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(Options{SecretVariables: true}).ConvertObject(objs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []SecretVariable{
+		{Name: "tls_dockerconfigjson", Secret: "web/tls", Key: ".dockerconfigjson"},
+		{Name: "tls_my_key", Secret: "web/tls", Key: "my-key"},
+		{Name: "tls_tls_crt", Secret: "web/tls", Key: "tls.crt"},
+	}, r.SecretVariables)
+
+	// Read our golden file (or optionally write if env var is set)
+	goldenFile := filepath.Join("../../test-fixtures", "secrets", "secret.tf.golden")
+	if update {
+		os.WriteFile(goldenFile, r.HCL, 0644)
+	}
+	expected := testLoadFile(t, goldenFile)
+
+	assert.Equal(t, expected, string(r.HCL), "should be equal")
+	assert.NotContains(t, string(r.HCL), "YWJj", "secret values should not be written")
+}
+
+func TestConverter_SecretVariables_unique(t *testing.T) {
+	objs, err := k8sparser.ParseYAML(strings.NewReader(testLoadFile(t, "../../test-fixtures", "secrets", "batch.yaml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := New(Options{SecretVariables: true}).Convert(objs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// keys normalized to the same name, and Secrets of the same name in different namespaces
+	assert.Equal(t, []SecretVariable{
+		{Name: "tls_my_key", Secret: "web/tls", Key: "my-key"},
+		{Name: "tls_my_key_2", Secret: "web/tls", Key: "my_key"},
+	}, results[0].SecretVariables)
+	assert.Equal(t, []SecretVariable{
+		{Name: "tls_my_key_3", Secret: "api/tls", Key: "my-key"},
+	}, results[1].SecretVariables)
+	assert.Contains(t, string(results[1].HCL), `variable "tls_my_key_3"`)
+}
+
+func TestConverter_SecretVariables_otherKinds(t *testing.T) {
+	objs, err := k8sparser.ParseYAML(strings.NewReader(testLoadFile(t, "../../test-fixtures", "configMap.yaml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(Options{SecretVariables: true}).ConvertObject(objs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Empty(t, r.SecretVariables)
+	assert.NotContains(t, string(r.HCL), "variable")
+}
+
+func TestWriteSecretVariablesTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSecretVariablesTemplate([]SecretVariable{
+		{Name: "tls_tls_crt", Secret: "web/tls", Key: "tls.crt"},
+		{Name: "db_password", Secret: "default/db", Key: "password"},
+	}, &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `# Secret web/tls, key "tls.crt"
+tls_tls_crt = ""
+# Secret default/db, key "password"
+db_password = ""
+`, buf.String())
+}
@@ -0,0 +1,495 @@
+// Package drift compares Terraform config generated from Kubernetes YAML with existing Terraform config,
+// to find resources that diverged since they were generated.
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// metaArguments are Terraform meta-arguments, which are never generated, so are not reported as drift
+// when added to the Terraform config by hand
+var metaArguments = map[string]bool{
+	"count":       true,
+	"depends_on":  true,
+	"for_each":    true,
+	"lifecycle":   true,
+	"provider":    true,
+	"provisioner": true,
+}
+
+// DifferenceKind classifies a Difference
+type DifferenceKind string
+
+const (
+	// Missing resources, attributes and blocks are generated from the YAML, but not found in the Terraform config
+	Missing DifferenceKind = "missing"
+	// Extra resources, attributes and blocks are found in the Terraform config, but not generated from the YAML
+	Extra DifferenceKind = "extra"
+	// Changed resources and attributes are found in both, with different values
+	Changed DifferenceKind = "changed"
+)
+
+// Difference describes an attribute or block of a resource that differs between the YAML and the Terraform config
+type Difference struct {
+	Kind DifferenceKind `json:"kind"`
+	// Path of the attribute or block, e.g. spec[0].template[0].spec[0].container[0].image
+	Path string `json:"path"`
+	// Want is the value generated from the YAML
+	Want string `json:"want,omitempty"`
+	// Got is the value in the Terraform config
+	Got string `json:"got,omitempty"`
+}
+
+// String returns a human readable description of the difference
+func (d Difference) String() string {
+	switch d.Kind {
+	case Missing:
+		return fmt.Sprintf("+ %s = %s", d.Path, d.Want)
+	case Extra:
+		return fmt.Sprintf("- %s = %s", d.Path, d.Got)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", d.Path, d.Got, d.Want)
+	}
+}
+
+// Resource is a Terraform resource block, and the file it was read from
+type Resource struct {
+	Address string
+	File    string
+	block   *hclsyntax.Block
+	src     []byte
+}
+
+// Parse returns the kubernetes_* resources in the given Terraform config
+func Parse(src []byte, filename string) ([]*Resource, error) {
+	p := hclparse.NewParser()
+	f, diags := p.ParseHCL(src, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return resources(f, filename), nil
+}
+
+// ParseFiles returns the kubernetes_* resources in the given Terraform config files
+func ParseFiles(filenames []string) ([]*Resource, error) {
+	p := hclparse.NewParser()
+
+	var res []*Resource
+	for _, fn := range filenames {
+		f, diags := p.ParseHCLFile(fn)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		res = append(res, resources(f, fn)...)
+	}
+
+	return res, nil
+}
+
+func resources(f *hcl.File, filename string) []*Resource {
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	var res []*Resource
+	for _, b := range body.Blocks {
+		if b.Type != "resource" || len(b.Labels) != 2 || !strings.HasPrefix(b.Labels[0], "kubernetes_") {
+			continue
+		}
+		res = append(res, &Resource{
+			Address: b.Labels[0] + "." + b.Labels[1],
+			File:    filename,
+			block:   b,
+			src:     f.Bytes,
+		})
+	}
+	return res
+}
+
+// ResourceDrift describes a resource that differs between the YAML and the Terraform config
+type ResourceDrift struct {
+	Kind    DifferenceKind `json:"kind"`
+	Address string         `json:"address"`
+	// Source is the file the YAML was read from
+	Source string `json:"source,omitempty"`
+	// File is the Terraform config file the resource was found in
+	File        string       `json:"file,omitempty"`
+	Differences []Difference `json:"differences,omitempty"`
+}
+
+// Summary counts the resources of a Report
+type Summary struct {
+	Resources int `json:"resources"`
+	Changed   int `json:"changed"`
+	Missing   int `json:"missing"`
+	Extra     int `json:"extra"`
+}
+
+// Report lists the resources that differ between the YAML and the Terraform config
+type Report struct {
+	Summary   Summary         `json:"summary"`
+	Resources []ResourceDrift `json:"resources"`
+}
+
+// Compare compares the resources generated from the YAML with the resources in the Terraform config.
+// sources maps the address of each generated resource to the file its YAML was read from.
+func Compare(want, got []*Resource, sources map[string]string) *Report {
+	rep := &Report{Resources: []ResourceDrift{}}
+
+	existing := map[string]*Resource{}
+	for _, r := range got {
+		existing[r.Address] = r
+	}
+
+	generated := map[string]bool{}
+	for _, w := range want {
+		generated[w.Address] = true
+		rep.Summary.Resources++
+
+		g, ok := existing[w.Address]
+		if !ok {
+			rep.add(ResourceDrift{Kind: Missing, Address: w.Address, Source: sources[w.Address]})
+			continue
+		}
+
+		c := &comparer{want: w.src, got: g.src}
+		c.compareBody(w.block.Body, g.block.Body, "")
+		if len(c.diffs) > 0 {
+			rep.add(ResourceDrift{Kind: Changed, Address: w.Address, Source: sources[w.Address], File: g.File, Differences: c.diffs})
+		}
+	}
+
+	for _, g := range got {
+		if !generated[g.Address] {
+			rep.Summary.Resources++
+			rep.add(ResourceDrift{Kind: Extra, Address: g.Address, File: g.File})
+		}
+	}
+
+	return rep
+}
+
+func (r *Report) add(d ResourceDrift) {
+	switch d.Kind {
+	case Changed:
+		r.Summary.Changed++
+	case Missing:
+		r.Summary.Missing++
+	case Extra:
+		r.Summary.Extra++
+	}
+	r.Resources = append(r.Resources, d)
+}
+
+// HasDrift returns true when any resource differs
+func (r *Report) HasDrift() bool {
+	return len(r.Resources) > 0
+}
+
+// WriteJSON writes the report to w as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report to w in a human readable format
+func (r *Report) WriteText(w io.Writer) error {
+	var sb strings.Builder
+
+	for _, d := range r.Resources {
+		switch d.Kind {
+		case Missing:
+			fmt.Fprintf(&sb, "+ %s: missing from Terraform config (%s)\n", d.Address, d.Source)
+		case Extra:
+			fmt.Fprintf(&sb, "- %s: not found in YAML (%s)\n", d.Address, d.File)
+		default:
+			fmt.Fprintf(&sb, "~ %s: %d difference(s) (%s, %s)\n", d.Address, len(d.Differences), d.Source, d.File)
+			for _, diff := range d.Differences {
+				fmt.Fprintf(&sb, "    %s\n", diff)
+			}
+		}
+	}
+
+	if r.HasDrift() {
+		fmt.Fprintf(&sb, "\n%d of %d resource(s) drifted: %d changed, %d missing from Terraform config, %d not found in YAML\n",
+			len(r.Resources), r.Summary.Resources, r.Summary.Changed, r.Summary.Missing, r.Summary.Extra)
+	} else {
+		fmt.Fprintf(&sb, "No drift found in %d resource(s)\n", r.Summary.Resources)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type comparer struct {
+	want, got []byte
+	diffs     []Difference
+}
+
+func (c *comparer) compareBody(want, got *hclsyntax.Body, path string) {
+	// an empty block, e.g. empty_dir {}, is written as an empty object attribute by --tf12format
+	empty := map[string]bool{}
+	for _, name := range attributeNames(want, got) {
+		if isEmptyBlockAttribute(want, got, name) || isEmptyBlockAttribute(got, want, name) {
+			empty[name] = true
+		}
+	}
+
+	for _, name := range attributeNames(want, got) {
+		if path == "" && metaArguments[name] || empty[name] {
+			continue
+		}
+		wa, wok := want.Attributes[name]
+		ga, gok := got.Attributes[name]
+		attrPath := joinPath(path, name)
+
+		switch {
+		case !gok:
+			c.diffs = append(c.diffs, Difference{Kind: Missing, Path: attrPath, Want: c.source(c.want, wa.Expr)})
+		case !wok:
+			c.diffs = append(c.diffs, Difference{Kind: Extra, Path: attrPath, Got: c.source(c.got, ga.Expr)})
+		default:
+			c.compareExpr(wa.Expr, ga.Expr, attrPath)
+		}
+	}
+
+	for _, ty := range blockTypes(want, got) {
+		if path == "" && metaArguments[ty] || empty[ty] {
+			continue
+		}
+		wantBlocks := blocksOfType(want, ty)
+		gotBlocks := blocksOfType(got, ty)
+
+		matched := map[*hclsyntax.Block]bool{}
+		for i, wb := range wantBlocks {
+			blockPath := fmt.Sprintf("%s[%d]", joinPath(path, ty), i)
+
+			gb := matchBlock(wb, wantBlocks, gotBlocks, matched)
+			if gb == nil {
+				c.diffs = append(c.diffs, Difference{Kind: Missing, Path: blockPath, Want: "{...}"})
+				continue
+			}
+			matched[gb] = true
+			c.compareBody(wb.Body, gb.Body, blockPath)
+		}
+
+		for i, gb := range gotBlocks {
+			if !matched[gb] {
+				c.diffs = append(c.diffs, Difference{Kind: Extra, Path: fmt.Sprintf("%s[%d]", joinPath(path, ty), i), Got: "{...}"})
+			}
+		}
+	}
+}
+
+// compareExpr compares the values of literal expressions, and the source of other expressions.
+// Differences in map values are reported per key.
+func (c *comparer) compareExpr(want, got hclsyntax.Expression, path string) {
+	wv, wok := literalValue(want)
+	gv, gok := literalValue(got)
+
+	if !wok || !gok {
+		if w, g := c.source(c.want, want), c.source(c.got, got); w != g {
+			c.diffs = append(c.diffs, Difference{Kind: Changed, Path: path, Want: w, Got: g})
+		}
+		return
+	}
+
+	c.compareValue(wv, gv, path)
+}
+
+func (c *comparer) compareValue(want, got cty.Value, path string) {
+	if want.RawEquals(got) {
+		return
+	}
+
+	if !isMap(want) || !isMap(got) {
+		c.diffs = append(c.diffs, Difference{Kind: Changed, Path: path, Want: formatValue(want), Got: formatValue(got)})
+		return
+	}
+
+	wm, gm := want.AsValueMap(), got.AsValueMap()
+	keys := map[string]bool{}
+	for k := range wm {
+		keys[k] = true
+	}
+	for k := range gm {
+		keys[k] = true
+	}
+
+	for _, k := range sortedKeys(keys) {
+		keyPath := joinPath(path, k)
+		wk, wok := wm[k]
+		gk, gok := gm[k]
+
+		switch {
+		case !gok:
+			c.diffs = append(c.diffs, Difference{Kind: Missing, Path: keyPath, Want: formatValue(wk)})
+		case !wok:
+			c.diffs = append(c.diffs, Difference{Kind: Extra, Path: keyPath, Got: formatValue(gk)})
+		default:
+			c.compareValue(wk, gk, keyPath)
+		}
+	}
+}
+
+// source returns the source of expr, on a single line
+func (c *comparer) source(src []byte, expr hclsyntax.Expression) string {
+	if v, ok := literalValue(expr); ok {
+		return formatValue(v)
+	}
+	return strings.Join(strings.Fields(string(expr.Range().SliceBytes(src))), " ")
+}
+
+// matchBlock returns the block of candidates that matches wb. Blocks with a literal name attribute
+// (e.g. container blocks) are matched by name, others by position.
+func matchBlock(wb *hclsyntax.Block, wantBlocks, candidates []*hclsyntax.Block, matched map[*hclsyntax.Block]bool) *hclsyntax.Block {
+	if name, ok := blockName(wb); ok {
+		for _, c := range candidates {
+			if n, ok := blockName(c); ok && n == name && !matched[c] {
+				return c
+			}
+		}
+	}
+
+	wantNames := map[string]bool{}
+	for _, b := range wantBlocks {
+		if n, ok := blockName(b); ok {
+			wantNames[n] = true
+		}
+	}
+	for _, c := range candidates {
+		if n, ok := blockName(c); !matched[c] && (!ok || !wantNames[n]) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func blockName(b *hclsyntax.Block) (string, bool) {
+	attr, ok := b.Body.Attributes["name"]
+	if !ok {
+		return "", false
+	}
+
+	v, ok := literalValue(attr.Expr)
+	if !ok || v.Type() != cty.String || v.IsNull() {
+		return "", false
+	}
+	return v.AsString(), true
+}
+
+// literalValue returns the value of an expression that doesn't reference variables or call functions
+func literalValue(expr hclsyntax.Expression) (cty.Value, bool) {
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() || !v.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+	return v, true
+}
+
+// isEmptyBlockAttribute returns true when a sets the named attribute to an empty object, and b has a single empty
+// block of that type instead
+func isEmptyBlockAttribute(a, b *hclsyntax.Body, name string) bool {
+	attr, ok := a.Attributes[name]
+	if !ok || len(blocksOfType(a, name)) > 0 {
+		return false
+	}
+	if _, ok := b.Attributes[name]; ok {
+		return false
+	}
+	v, ok := literalValue(attr.Expr)
+	if !ok || !isMap(v) || v.LengthInt() > 0 {
+		return false
+	}
+
+	blocks := blocksOfType(b, name)
+	return len(blocks) == 1 && len(blocks[0].Body.Attributes) == 0 && len(blocks[0].Body.Blocks) == 0
+}
+
+func isMap(v cty.Value) bool {
+	return !v.IsNull() && (v.Type().IsObjectType() || v.Type().IsMapType())
+}
+
+// formatValue returns a short HCL representation of v
+func formatValue(v cty.Value) string {
+	switch {
+	case v.IsNull():
+		return "null"
+	case v.Type() == cty.String:
+		return strconv.Quote(v.AsString())
+	case v.Type() == cty.Number:
+		return v.AsBigFloat().Text('f', -1)
+	case v.Type() == cty.Bool:
+		return strconv.FormatBool(v.True())
+	case isMap(v):
+		return "{...}"
+	default:
+		return "[...]"
+	}
+}
+
+func attributeNames(a, b *hclsyntax.Body) []string {
+	names := map[string]bool{}
+	for name := range a.Attributes {
+		names[name] = true
+	}
+	for name := range b.Attributes {
+		names[name] = true
+	}
+	return sortedKeys(names)
+}
+
+// blockTypes returns the types of the blocks of a and b, in the order they first appear
+func blockTypes(a, b *hclsyntax.Body) []string {
+	var types []string
+	seen := map[string]bool{}
+	for _, blocks := range [][]*hclsyntax.Block{a.Blocks, b.Blocks} {
+		for _, bl := range blocks {
+			if !seen[bl.Type] {
+				seen[bl.Type] = true
+				types = append(types, bl.Type)
+			}
+		}
+	}
+	return types
+}
+
+func blocksOfType(body *hclsyntax.Body, ty string) []*hclsyntax.Block {
+	var blocks []*hclsyntax.Block
+	for _, b := range body.Blocks {
+		if b.Type == ty {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
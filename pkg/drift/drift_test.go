@@ -0,0 +1,229 @@
+package drift
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const generatedConfig = `resource "kubernetes_deployment" "web" {
+  metadata {
+    name = "web"
+    labels = {
+      app  = "web"
+      tier = "frontend"
+    }
+  }
+  spec {
+    replicas = 3
+    template {
+      spec {
+        container {
+          name  = "web"
+          image = "web:2.0"
+        }
+        container {
+          name  = "sidecar"
+          image = "envoy:1.0"
+        }
+      }
+    }
+  }
+}
+
+resource "kubernetes_service" "web" {
+  metadata {
+    name = "web"
+  }
+}
+`
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     []ResourceDrift
+	}{
+		{
+			"no drift",
+			generatedConfig,
+			[]ResourceDrift{},
+		},
+		{
+			"changed",
+			`resource "kubernetes_deployment" "web" {
+  metadata {
+    name = "web"
+    labels = {
+      app = "web"
+      team = "a"
+    }
+  }
+  spec {
+    replicas = var.replicas
+    paused   = false
+    template {
+      spec {
+        container {
+          name  = "sidecar"
+          image = "envoy:1.0"
+        }
+        container {
+          name  = "web"
+          image = "web:1.0"
+        }
+      }
+    }
+  }
+
+  lifecycle {
+    ignore_changes = [spec[0].replicas]
+  }
+}
+
+resource "kubernetes_service" "web" {
+  metadata {
+    name = "web"
+  }
+}
+`,
+			[]ResourceDrift{
+				{
+					Kind:    Changed,
+					Address: "kubernetes_deployment.web",
+					Source:  "web.yaml",
+					File:    "main.tf",
+					Differences: []Difference{
+						{Kind: Extra, Path: "metadata[0].labels.team", Got: `"a"`},
+						{Kind: Missing, Path: "metadata[0].labels.tier", Want: `"frontend"`},
+						{Kind: Extra, Path: "spec[0].paused", Got: "false"},
+						{Kind: Changed, Path: "spec[0].replicas", Want: "3", Got: "var.replicas"},
+						{Kind: Changed, Path: "spec[0].template[0].spec[0].container[0].image", Want: `"web:2.0"`, Got: `"web:1.0"`},
+					},
+				},
+			},
+		},
+		{
+			"missing and extra resources",
+			`resource "kubernetes_deployment" "web" {
+  metadata {
+    name = "web"
+    labels = {
+      tier = "frontend"
+      app  = "web"
+    }
+  }
+  spec {
+    replicas = 3
+    template {
+      spec {
+        container {
+          name  = "web"
+          image = "web:2.0"
+        }
+        container {
+          name  = "sidecar"
+          image = "envoy:1.0"
+        }
+      }
+    }
+  }
+}
+
+resource "kubernetes_config_map" "old" {
+  metadata {
+    name = "old"
+  }
+}
+
+resource "random_id" "suffix" {
+  byte_length = 4
+}
+`,
+			[]ResourceDrift{
+				{Kind: Missing, Address: "kubernetes_service.web", Source: "web.yaml"},
+				{Kind: Extra, Address: "kubernetes_config_map.old", File: "main.tf"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := Parse([]byte(generatedConfig), "generated.tf")
+			require.NoError(t, err)
+			got, err := Parse([]byte(tt.existing), "main.tf")
+			require.NoError(t, err)
+
+			sources := map[string]string{
+				"kubernetes_deployment.web": "web.yaml",
+				"kubernetes_service.web":    "web.yaml",
+			}
+			rep := Compare(want, got, sources)
+
+			assert.Equal(t, tt.want, rep.Resources)
+			assert.Equal(t, len(tt.want) > 0, rep.HasDrift())
+		})
+	}
+}
+
+func TestCompare_emptyBlock(t *testing.T) {
+	block := `resource "kubernetes_pod" "cache" {
+  spec {
+    volume {
+      name = "data"
+      empty_dir {}
+    }
+  }
+}
+`
+	// written by --tf12format
+	attribute := `resource "kubernetes_pod" "cache" {
+  spec {
+    volume {
+      name      = "data"
+      empty_dir = {}
+    }
+  }
+}
+`
+	want, err := Parse([]byte(block), "generated.tf")
+	require.NoError(t, err)
+	got, err := Parse([]byte(attribute), "main.tf")
+	require.NoError(t, err)
+
+	assert.False(t, Compare(want, got, nil).HasDrift())
+	assert.False(t, Compare(got, want, nil).HasDrift())
+}
+
+func TestReport_WriteText(t *testing.T) {
+	rep := &Report{}
+	rep.Summary.Resources = 3
+	rep.add(ResourceDrift{
+		Kind:    Changed,
+		Address: "kubernetes_deployment.web",
+		Source:  "web.yaml",
+		File:    "main.tf",
+		Differences: []Difference{
+			{Kind: Changed, Path: "spec[0].replicas", Want: "3", Got: "2"},
+			{Kind: Missing, Path: "metadata[0].labels.tier", Want: `"frontend"`},
+		},
+	})
+	rep.add(ResourceDrift{Kind: Missing, Address: "kubernetes_service.web", Source: "web.yaml"})
+	rep.add(ResourceDrift{Kind: Extra, Address: "kubernetes_config_map.old", File: "main.tf"})
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteText(&buf))
+	assert.Equal(t, `~ kubernetes_deployment.web: 2 difference(s) (web.yaml, main.tf)
+    ~ spec[0].replicas: 2 -> 3
+    + metadata[0].labels.tier = "frontend"
++ kubernetes_service.web: missing from Terraform config (web.yaml)
+- kubernetes_config_map.old: not found in YAML (main.tf)
+
+3 of 3 resource(s) drifted: 1 changed, 1 missing from Terraform config, 1 not found in YAML
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, (&Report{Summary: Summary{Resources: 2}}).WriteText(&buf))
+	assert.Equal(t, "No drift found in 2 resource(s)\n", buf.String())
+}
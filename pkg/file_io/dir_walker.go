@@ -0,0 +1,176 @@
+package file_io
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/rs/zerolog/log"
+)
+
+// IgnoreFileName is the name of the file holding exclude patterns, one per line,
+// read from the root of the input directory when InputOptions.UseIgnoreFile is set.
+const IgnoreFileName = ".k2tfignore"
+
+// DefaultIncludes are the file patterns read from input directories when no Include patterns are given
+var DefaultIncludes = []string{"*.yaml", "*.yml"}
+
+// pathMatcher matches slash separated paths, relative to the input directory, against glob patterns.
+// Patterns without a slash are matched against the base name only, so "*.yaml" matches at any depth.
+// "**" matches across directory separators, e.g. "base/**/*.yaml".
+type pathMatcher struct {
+	globs []glob.Glob
+	base  []bool
+}
+
+func newPathMatcher(patterns []string) *pathMatcher {
+	m := &pathMatcher{}
+	for _, p := range patterns {
+		p = strings.TrimSuffix(strings.TrimPrefix(p, "/"), "/")
+		if p == "" {
+			continue
+		}
+
+		g, err := glob.Compile(p, '/')
+		if err != nil {
+			log.Fatal().Err(err).Str("pattern", p).Msg("invalid glob pattern")
+		}
+		m.globs = append(m.globs, g)
+		m.base = append(m.base, !strings.Contains(p, "/"))
+	}
+	return m
+}
+
+func (m *pathMatcher) match(rel string) bool {
+	for i, g := range m.globs {
+		if m.base[i] && g.Match(path.Base(rel)) {
+			return true
+		}
+		if g.Match(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// dirWalker finds the files to read in an input directory
+type dirWalker struct {
+	root      string
+	recursive bool
+	include   *pathMatcher
+	exclude   *pathMatcher
+
+	// visited tracks the real path of each directory read, to guard against symlink loops
+	visited map[string]bool
+	files   []string
+}
+
+func newDirWalker(root string, opts InputOptions) *dirWalker {
+	includes := opts.Include
+	if len(includes) == 0 {
+		includes = DefaultIncludes
+	}
+
+	excludes := opts.Exclude
+	if opts.UseIgnoreFile {
+		excludes = append(excludes, readIgnoreFile(filepath.Join(root, IgnoreFileName))...)
+	}
+
+	return &dirWalker{
+		root:      root,
+		recursive: opts.Recursive,
+		include:   newPathMatcher(includes),
+		exclude:   newPathMatcher(excludes),
+		visited:   map[string]bool{},
+	}
+}
+
+// walk returns the files in the input directory that should be read, in lexical order.
+func (w *dirWalker) walk() []string {
+	w.walkDir(w.root, "")
+	return w.files
+}
+
+func (w *dirWalker) walkDir(dir, rel string) {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		log.Warn().Err(err).Str("dir", dir).Msg("could not resolve directory")
+		return
+	}
+	if w.visited[real] {
+		log.Warn().Str("dir", dir).Msg("skipping directory, already visited (symlink loop?)")
+		return
+	}
+	w.visited[real] = true
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+
+	for _, e := range entries {
+		name := filepath.Join(dir, e.Name())
+		entryRel := path.Join(rel, e.Name())
+
+		if w.exclude.match(entryRel) {
+			log.Debug().Str("path", name).Msg("excluding path")
+			continue
+		}
+
+		info, err := os.Stat(name)
+		if err != nil {
+			// e.g. a broken symlink
+			log.Warn().Err(err).Str("path", name).Msg("skipping path")
+			continue
+		}
+
+		if info.IsDir() {
+			if !w.recursive {
+				continue
+			}
+			if isKustomization(name) || isHelmChart(name) {
+				log.Warn().Str("dir", name).Msg("skipping kustomization / Helm chart directory, convert it directly with -f")
+				continue
+			}
+			w.walkDir(name, entryRel)
+
+		} else if info.Mode().IsRegular() && w.include.match(entryRel) {
+			w.files = append(w.files, name)
+		}
+	}
+}
+
+// readIgnoreFile reads exclude patterns from the named file.
+// Blank lines, and lines starting with '#' are ignored.
+func readIgnoreFile(name string) []string {
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		log.Fatal().Err(err).Str("file", name).Msg("could not read ignore file")
+	}
+	defer f.Close()
+
+	var patterns []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "!") {
+			log.Warn().Str("file", name).Str("pattern", line).Msg("negated ignore patterns are not supported")
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := s.Err(); err != nil {
+		log.Fatal().Err(err).Str("file", name).Msg("could not read ignore file")
+	}
+
+	return patterns
+}
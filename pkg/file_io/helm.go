@@ -0,0 +1,152 @@
+package file_io
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+
+	"github.com/rs/zerolog/log"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/strvals"
+)
+
+const (
+	defaultHelmReleaseName = "release-name"
+	defaultHelmNamespace   = "default"
+)
+
+// HelmOptions configures how a Helm chart is rendered
+type HelmOptions struct {
+	// ValuesFiles are merged in order, with later files taking precedence
+	ValuesFiles []string
+	// Set holds values in the `helm --set` format, e.g. "image.tag=1.2.3,replicas=2".
+	// They take precedence over ValuesFiles.
+	Set []string
+	// ReleaseName is the name of the release used when rendering templates
+	ReleaseName string
+	// Namespace is the namespace of the release used when rendering templates
+	Namespace string
+}
+
+// isHelmChart returns true if the input is a chart directory, or a packaged chart archive
+func isHelmChart(input string) bool {
+	if strings.HasSuffix(input, ".tgz") {
+		return true
+	}
+
+	_, err := os.Stat(filepath.Join(input, chartutil.ChartfileName))
+	return err == nil
+}
+
+// readHelmChart renders the templates of a local Helm chart, and parses the rendered
+// Kubernetes objects. The chart template each object was rendered from is recorded as its Source.
+func (in *Input) readHelmChart(input string, opts HelmOptions) {
+	log.Debug().Msgf("rendering helm chart: %s", input)
+
+	chrt, err := loader.Load(input)
+	if err != nil {
+		log.Fatal().Err(err).Str("chart", input).Msg("could not load helm chart")
+	}
+
+	vals, err := helmValues(opts)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not read helm values")
+	}
+
+	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
+		log.Fatal().Err(err).Msg("could not process helm chart dependencies")
+	}
+
+	releaseOpts := chartutil.ReleaseOptions{
+		Name:      opts.ReleaseName,
+		Namespace: opts.Namespace,
+		Revision:  1,
+		IsInstall: true,
+	}
+	if releaseOpts.Name == "" {
+		releaseOpts.Name = defaultHelmReleaseName
+	}
+	if releaseOpts.Namespace == "" {
+		releaseOpts.Namespace = defaultHelmNamespace
+	}
+
+	renderVals, err := chartutil.ToRenderValues(chrt, vals, releaseOpts, chartutil.DefaultCapabilities)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not prepare helm values")
+	}
+
+	rendered, err := engine.Render(chrt, renderVals)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not render helm chart")
+	}
+
+	// render in a stable order
+	templates := make([]string, 0, len(rendered))
+	for name := range rendered {
+		templates = append(templates, name)
+	}
+	sort.Strings(templates)
+
+	for _, name := range templates {
+		content := rendered[name]
+		if strings.HasSuffix(name, "NOTES.txt") || strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		log.Debug().Msgf("parsing helm template: %s", name)
+		parsed, err := k8sparser.ParseYAML(strings.NewReader(content))
+		if err != nil {
+			log.Warn().Err(err).Str("template", name).Msg("could not parse rendered template")
+			in.recordParseError(name, err)
+		}
+
+		in.add(parsed, name)
+	}
+}
+
+// helmValues merges the values files and --set values into a single values map
+func helmValues(opts HelmOptions) (map[string]interface{}, error) {
+	vals := map[string]interface{}{}
+
+	for _, f := range opts.ValuesFiles {
+		fileVals, err := chartutil.ReadValuesFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("could not read values file %s: %w", f, err)
+		}
+		vals = mergeValues(vals, fileVals)
+	}
+
+	for _, s := range opts.Set {
+		if err := strvals.ParseInto(s, vals); err != nil {
+			return nil, fmt.Errorf("could not parse --set value %q: %w", s, err)
+		}
+	}
+
+	return vals, nil
+}
+
+// mergeValues deep merges b into a, with b taking precedence
+func mergeValues(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+
+	for k, v := range b {
+		if bv, ok := v.(map[string]interface{}); ok {
+			if av, ok := out[k].(map[string]interface{}); ok {
+				out[k] = mergeValues(av, bv)
+				continue
+			}
+		}
+		out[k] = v
+	}
+
+	return out
+}
@@ -0,0 +1,67 @@
+package file_io
+
+import (
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func Test_readHelmChart(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         HelmOptions
+		wantObjCount int
+		wantName     string
+		wantReplicas int32
+		wantImage    string
+	}{
+		{
+			"defaults",
+			HelmOptions{},
+			2,
+			"release-name-mychart",
+			1,
+			"nginx:1.16.0",
+		},
+		{
+			"values",
+			HelmOptions{
+				ValuesFiles: []string{"../../test-fixtures/helm/values-prod.yaml"},
+				Set:         []string{"service.enabled=false,image.repository=myregistry/nginx"},
+				ReleaseName: "web",
+			},
+			1,
+			"web-mychart",
+			3,
+			"myregistry/nginx:1.17.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := readFilesInput("../../test-fixtures/helm/mychart", InputOptions{Helm: tt.opts})
+			objs := in.Objects
+			if !assert.Len(t, objs, tt.wantObjCount) {
+				return
+			}
+
+			deploy, ok := objs[0].(*appsv1.Deployment)
+			if !assert.True(t, ok, "expected a Deployment, got %T", objs[0]) {
+				return
+			}
+			assert.Equal(t, tt.wantName, k8sutils.ObjectMeta(deploy).Name)
+			assert.Equal(t, tt.wantReplicas, *deploy.Spec.Replicas)
+			assert.Equal(t, tt.wantImage, deploy.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(t, "mychart/templates/deployment.yaml", in.Source(deploy))
+		})
+	}
+}
+
+func TestIsHelmChart(t *testing.T) {
+	assert.True(t, IsHelmChart("../../test-fixtures/helm/mychart"))
+	assert.True(t, IsHelmChart("mychart-0.1.0.tgz"))
+	assert.False(t, IsHelmChart("../../test-fixtures/helm"))
+	assert.False(t, IsHelmChart("../../test-fixtures/service.yaml"))
+	assert.False(t, IsHelmChart("-"))
+}
@@ -4,25 +4,48 @@ import (
 	"bufio"
 	"bytes"
 	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
 
 	"github.com/rs/zerolog/log"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func ReadInput(input string) []runtime.Object {
+// InputOptions configures how input is read
+type InputOptions struct {
+	// Recursive reads files in sub-directories of an input directory.
+	// Sub-directories containing a kustomization or Helm chart are skipped.
+	Recursive bool
+	// Include holds glob patterns of the files to read from an input directory.
+	// Defaults to DefaultIncludes.
+	Include []string
+	// Exclude holds glob patterns of files and directories that are skipped
+	Exclude []string
+	// UseIgnoreFile reads additional Exclude patterns from the IgnoreFileName file in the input directory
+	UseIgnoreFile bool
+
+	// Helm configures how Helm chart input is rendered
+	Helm HelmOptions
+}
+
+// ReadInput reads Kubernetes objects from stdin, a file, a directory of files, a kustomization or a Helm chart
+func ReadInput(input string, opts InputOptions) *Input {
 	if input == "-" || input == "" {
 		return readStdinInput(input)
 	}
-	return readFilesInput(input)
+	return readFilesInput(input, opts)
 }
 
-func readStdinInput(input string) []runtime.Object {
-	var objs []runtime.Object
+// IsHelmChart returns true if the input is a Helm chart directory or archive
+func IsHelmChart(input string) bool {
+	if input == "-" || input == "" {
+		return false
+	}
+	return isHelmChart(input)
+}
+
+func readStdinInput(input string) *Input {
+	in := &Input{}
 
 	info, err := os.Stdin.Stat()
 	if err != nil {
@@ -37,7 +60,8 @@ func readStdinInput(input string) []runtime.Object {
 	parsed, err := k8sparser.ParseYAML(reader)
 
 	if err != nil {
-		log.Fatal().Err(err).Msg("Could not parse stdin")
+		log.Warn().Err(err).Msg("could not parse stdin")
+		in.recordParseError("<stdin>", err)
 	}
 
 	for _, obj := range parsed {
@@ -46,29 +70,35 @@ func readStdinInput(input string) []runtime.Object {
 			for _, item := range list.Items {
 				itemObj, err := k8sparser.ParseJSON(item.Raw)
 				if err != nil {
-					log.Error().Err(err)
+					log.Warn().Err(err).Msg("could not parse list item from stdin")
+					in.recordParseError("<stdin>", err)
 					continue
 				}
-				objs = append(objs, itemObj)
+				in.Objects = append(in.Objects, itemObj)
 
 			}
 
 		} else {
-			objs = append(objs, obj)
+			in.Objects = append(in.Objects, obj)
 
 		}
 	}
 
-	return objs
+	return in
 }
 
-func readFilesInput(input string) []runtime.Object {
-	var objs []runtime.Object
+func readFilesInput(input string, opts InputOptions) *Input {
+	in := &Input{}
 
 	if _, err := os.Stat(input); os.IsNotExist(err) {
 		log.Fatal().Str("file", input).Msg("input filepath does not exist")
 	}
 
+	if isHelmChart(input) {
+		in.readHelmChart(input, opts.Helm)
+		return in
+	}
+
 	file, err := os.Open(input)
 	if err != nil {
 		log.Fatal().Err(err).Msg("")
@@ -90,24 +120,23 @@ func readFilesInput(input string) []runtime.Object {
 		r := bytes.NewReader(content)
 		obj, err := k8sparser.ParseYAML(r)
 		if err != nil {
-			log.Warn().Err(err).Msg("could not parse file")
+			log.Warn().Err(err).Str("file", fileName).Msg("could not parse file")
+			in.recordParseError(fileName, err)
 		}
-		objs = append(objs, obj...)
+
+		in.add(obj, fileName)
 	}
 
-	if fs.Mode().IsDir() {
+	if fs.Mode().IsDir() && isKustomization(input) {
+		// build kustomization
+		in.readKustomization(input)
+
+	} else if fs.Mode().IsDir() {
 		// read directory
 		log.Debug().Msgf("reading directory: %s", input)
 
-		dirContents, err := file.Readdirnames(0)
-		if err != nil {
-			log.Fatal().Err(err).Msg("")
-		}
-
-		for _, f := range dirContents {
-			if strings.HasSuffix(f, ".yml") || strings.HasSuffix(f, ".yaml") {
-				readFile(filepath.Join(input, f))
-			}
+		for _, f := range newDirWalker(input, opts).walk() {
+			readFile(f)
 		}
 
 	} else {
@@ -116,5 +145,5 @@ func readFilesInput(input string) []runtime.Object {
 
 	}
 
-	return objs
+	return in
 }
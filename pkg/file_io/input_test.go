@@ -1,7 +1,10 @@
 package file_io
 
 import (
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_readFilesInput(t *testing.T) {
@@ -38,9 +41,87 @@ func Test_readFilesInput(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := readFilesInput(tt.input); len(got) != tt.wantObjCount {
+			if got := readFilesInput(tt.input, InputOptions{}).Objects; len(got) != tt.wantObjCount {
 				t.Errorf("readFilesInput() object Count = %d, want %d", len(got), tt.wantObjCount)
 			}
 		})
 	}
 }
+
+func Test_readFilesInput_directoryOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      InputOptions
+		wantFiles []string
+	}{
+		{
+			"top level only",
+			InputOptions{},
+			[]string{"namespace.yaml"},
+		},
+		{
+			"recursive",
+			InputOptions{Recursive: true},
+			[]string{"apps/skip/configMap.yaml", "apps/web/deployment.yml", "apps/web/service.yaml", "namespace.yaml"},
+		},
+		{
+			"recursive with ignore file",
+			InputOptions{Recursive: true, UseIgnoreFile: true},
+			[]string{"apps/web/deployment.yml", "apps/web/service.yaml", "namespace.yaml"},
+		},
+		{
+			"include",
+			InputOptions{Recursive: true, Include: []string{"*.yml"}},
+			[]string{"apps/web/deployment.yml"},
+		},
+		{
+			"exclude path",
+			InputOptions{Recursive: true, Exclude: []string{"apps/**/*.yaml"}},
+			[]string{"apps/web/deployment.yml", "namespace.yaml"},
+		},
+		{
+			"exclude directory",
+			InputOptions{Recursive: true, Exclude: []string{"web/"}},
+			[]string{"apps/skip/configMap.yaml", "namespace.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := "../../test-fixtures/recursive"
+
+			in := readFilesInput(root, tt.opts)
+
+			var gotFiles []string
+			for _, obj := range in.Objects {
+				rel, err := filepath.Rel(root, in.Source(obj))
+				assert.NoError(t, err)
+				gotFiles = append(gotFiles, filepath.ToSlash(rel))
+			}
+
+			assert.Equal(t, tt.wantFiles, gotFiles)
+		})
+	}
+}
+
+func Test_readFilesInput_sources(t *testing.T) {
+	service := readFilesInput("../../test-fixtures/service.yaml", InputOptions{})
+	deployment := readFilesInput("../../test-fixtures/deployment.yaml", InputOptions{})
+
+	// each input only knows the sources of the objects it read
+	assert.Equal(t, "../../test-fixtures/service.yaml", service.Source(service.Objects[0]))
+	assert.Equal(t, "../../test-fixtures/deployment.yaml", deployment.Source(deployment.Objects[0]))
+	assert.Empty(t, service.Source(deployment.Objects[0]))
+}
+
+func Test_readFilesInput_parseErrors(t *testing.T) {
+	in := readFilesInput("../../test-fixtures/parse_errors", InputOptions{})
+
+	assert.Len(t, in.Objects, 2, "objects parsed before and around the error should be returned")
+	if assert.Len(t, in.ParseErrors, 2) {
+		assert.Equal(t, filepath.Join("../../test-fixtures/parse_errors", "invalid.yaml"), in.ParseErrors[0].Source)
+		assert.NotEmpty(t, in.ParseErrors[0].Error)
+		// a known kind with an invalid field is an error, not an unstructured object
+		assert.Equal(t, filepath.Join("../../test-fixtures/parse_errors", "invalidField.yaml"), in.ParseErrors[1].Source)
+		assert.Contains(t, in.ParseErrors[1].Error, "main scheme")
+	}
+}
@@ -0,0 +1,93 @@
+package file_io
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+
+	"github.com/rs/zerolog/log"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// originAnnotation is the annotation added by kustomize to record the file a resource was read from
+const originAnnotation = "config.kubernetes.io/origin"
+
+// isKustomization returns true if the input directory contains a kustomization file
+func isKustomization(input string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if _, err := os.Stat(filepath.Join(input, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// readKustomization builds the kustomization in the input directory, and parses the resulting
+// Kubernetes objects. Only resources on the local filesystem, within the kustomization root, are supported.
+func (in *Input) readKustomization(input string) {
+	log.Debug().Msgf("building kustomization: %s", input)
+
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := k.Run(filesys.MakeFsOnDisk(), input)
+	if err != nil {
+		log.Fatal().Err(err).Str("kustomization", input).Msg("could not build kustomization")
+	}
+
+	for _, res := range resMap.Resources() {
+		source := kustomizeSource(input, res)
+
+		content, err := res.AsYAML()
+		if err != nil {
+			log.Warn().Err(err).Str("resource", res.CurId().String()).Msg("could not render kustomize resource")
+			in.recordParseError(source, err)
+			continue
+		}
+
+		parsed, err := k8sparser.ParseYAML(bytes.NewReader(content))
+		if err != nil {
+			log.Warn().Err(err).Str("resource", res.CurId().String()).Msg("could not parse kustomize resource")
+			in.recordParseError(source, err)
+		}
+
+		in.add(parsed, source)
+	}
+}
+
+// kustomizeSource returns the file a kustomize resource was read from, or the kustomization file that
+// generated it, when the kustomization adds origin annotations (buildMetadata: [originAnnotations]), or else
+// the kustomization directory. The origin annotation
+// is removed from the resource, so that it's not part of the generated config.
+func kustomizeSource(input string, res *resource.Resource) string {
+	origin, err := res.GetOrigin()
+	if err != nil {
+		log.Warn().Err(err).Str("resource", res.CurId().String()).Msg("could not read kustomize resource origin")
+	}
+	if origin == nil {
+		return input
+	}
+
+	annotations := res.GetAnnotations()
+	delete(annotations, originAnnotation)
+	if err := res.SetAnnotations(annotations); err != nil {
+		log.Warn().Err(err).Str("resource", res.CurId().String()).Msg("could not remove kustomize resource origin")
+	}
+
+	path := origin.Path
+	if path == "" {
+		// generated resources, e.g. by a configMapGenerator, have the kustomization file they're configured in
+		path = origin.ConfiguredIn
+	}
+	switch {
+	case origin.Repo != "":
+		return origin.Repo + "//" + path
+	case path != "":
+		return filepath.Join(input, path)
+	default:
+		return input
+	}
+}
@@ -0,0 +1,87 @@
+package file_io
+
+import (
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func Test_readKustomization(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantObjCount  int
+		wantName      string
+		wantNamespace string
+		wantReplicas  int32
+		wantImage     string
+	}{
+		{
+			"base",
+			"../../test-fixtures/kustomize/base",
+			3,
+			"web",
+			"",
+			1,
+			"nginx:1.16.0",
+		},
+		{
+			"overlay",
+			"../../test-fixtures/kustomize/overlays/prod",
+			3,
+			"prod-web",
+			"prod",
+			3,
+			"nginx:1.17.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := readFilesInput(tt.input, InputOptions{})
+			objs := in.Objects
+			if !assert.Len(t, objs, tt.wantObjCount) {
+				return
+			}
+
+			var deploy *appsv1.Deployment
+			for _, obj := range objs {
+				if d, ok := obj.(*appsv1.Deployment); ok {
+					deploy = d
+				}
+			}
+			if !assert.NotNil(t, deploy) {
+				return
+			}
+
+			meta := k8sutils.ObjectMeta(deploy)
+			assert.Equal(t, tt.wantName, meta.Name)
+			assert.Equal(t, tt.wantNamespace, meta.Namespace)
+			assert.Equal(t, "web", meta.Labels["app"])
+			assert.Equal(t, tt.wantReplicas, *deploy.Spec.Replicas)
+			assert.Equal(t, tt.wantImage, deploy.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(t, tt.input, in.Source(deploy))
+		})
+	}
+}
+
+func Test_readKustomization_origin(t *testing.T) {
+	input := "../../test-fixtures/kustomize/overlays/origin"
+	in := readFilesInput(input, InputOptions{})
+	if !assert.Len(t, in.Objects, 3) {
+		return
+	}
+
+	var sources []string
+	for _, obj := range in.Objects {
+		sources = append(sources, in.Source(obj))
+		// the origin annotation is only used for the source
+		assert.NotContains(t, k8sutils.ObjectMeta(obj).Annotations, originAnnotation)
+	}
+	assert.ElementsMatch(t, []string{
+		"../../test-fixtures/kustomize/base/deployment.yaml",
+		"../../test-fixtures/kustomize/base/service.yaml",
+		"../../test-fixtures/kustomize/base/kustomization.yaml",
+	}, sources)
+}
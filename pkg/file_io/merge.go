@@ -0,0 +1,57 @@
+package file_io
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/sl1pm4t/k2tf/pkg/merge"
+
+	"github.com/rs/zerolog/log"
+)
+
+// mergeFile buffers generated config, and merges it into the existing content of the file when closed
+type mergeFile struct {
+	name string
+	buf  bytes.Buffer
+}
+
+// SetupMergeOutput returns a writer for generated config that is merged into the existing content of the
+// output file, preserving manual edits, instead of overwriting it.
+func SetupMergeOutput(output string) (io.Writer, CloseFunc) {
+	if output == "" || output == "-" {
+		log.Fatal().Msg("merging requires an output file")
+	}
+
+	f := &mergeFile{name: output}
+	return &f.buf, f.close
+}
+
+func (f *mergeFile) close() {
+	existing, err := os.ReadFile(f.name)
+	if os.IsNotExist(err) {
+		log.Debug().Str("file", f.name).Msg("nothing to merge, writing new file")
+		if err := os.WriteFile(f.name, f.buf.Bytes(), 0755); err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		return
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+
+	merged, stats, err := merge.Merge(existing, f.buf.Bytes(), f.name)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", f.name).Msg("could not merge output file")
+	}
+
+	if err := os.WriteFile(f.name, merged, 0755); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	log.Info().
+		Str("file", f.name).
+		Int("added", stats.Added).
+		Int("updated", stats.Updated).
+		Int("kept", stats.Kept).
+		Msg("merged output file")
+}
@@ -1,40 +1,70 @@
 package file_io
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
 
 	"github.com/rs/zerolog/log"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var noOpCloser = func() {}
 
 type CloseFunc func()
 
+// Layout determines how converted objects are split across output files
+type Layout string
+
+const (
+	// LayoutSingle writes all objects to a single file, or Stdout
+	LayoutSingle Layout = "single"
+	// LayoutObject writes one file per Kubernetes object
+	LayoutObject Layout = "object"
+	// LayoutNamespace writes one file per Kubernetes namespace
+	LayoutNamespace Layout = "namespace"
+	// LayoutType writes one file per Terraform resource type
+	LayoutType Layout = "type"
+)
+
+// Layouts lists all supported output layouts
+var Layouts = []Layout{LayoutSingle, LayoutObject, LayoutNamespace, LayoutType}
+
+// ParseLayout validates the given layout name
+func ParseLayout(s string) (Layout, error) {
+	for _, l := range Layouts {
+		if string(l) == s {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output layout %q, must be one of %v", s, Layouts)
+}
+
+const (
+	// clusterScopedFileName is used by LayoutNamespace for objects that are not namespaced
+	clusterScopedFileName = "cluster"
+	// defaultNamespaceFileName is used by LayoutNamespace for namespaced objects with no namespace set
+	defaultNamespaceFileName = "default"
+
+	manifestResourceType = "kubernetes_manifest"
+)
+
 func SetupOutput(output string, overwriteExisting bool) (io.Writer, CloseFunc) {
 	var closeFn CloseFunc
 	var w io.Writer
 
 	if output != "" && output != "-" {
 		// writing to a file
-
-		if _, err := os.Stat(output); err == nil && !overwriteExisting {
-			// don't clobber
-			log.Fatal().Str("file", output).Msg("output file already exists")
-		}
-
-		f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+		f := openOutputFile(output, overwriteExisting)
 		w = f
-		if err != nil {
-			log.Fatal().Err(err).Msg("")
-		}
-		log.Debug().Str("file", output).Msg("opened file")
 
 		closeFn = func() {
-			if err := f.Close(); err != nil {
-				log.Fatal().Err(err).Msg("")
-			}
-			log.Debug().Str("file", output).Msg("closed output file")
+			closeOutputFile(f)
 		}
 
 	} else {
@@ -46,3 +76,150 @@ func SetupOutput(output string, overwriteExisting bool) (io.Writer, CloseFunc) {
 
 	return w, closeFn
 }
+
+// DirectoryOutput distributes converted objects across multiple files in a directory,
+// according to the configured Layout.
+type DirectoryOutput struct {
+	dir               string
+	layout            Layout
+	overwriteExisting bool
+	merge             bool
+
+	// Extension replaces the .tf extension of output file names when set, e.g. with .tf.json
+	Extension string
+
+	files   map[string]io.Writer
+	closers []CloseFunc
+}
+
+// SetupDirectoryOutput prepares the output directory, creating it if required.
+// Output files are opened lazily the first time an object is written to them.
+func SetupDirectoryOutput(dir string, layout Layout, overwriteExisting bool) (*DirectoryOutput, CloseFunc) {
+	return setupDirectoryOutput(dir, layout, overwriteExisting, false)
+}
+
+// SetupDirectoryMergeOutput is like SetupDirectoryOutput, but generated config is merged into existing output
+// files, preserving manual edits, instead of overwriting them.
+func SetupDirectoryMergeOutput(dir string, layout Layout) (*DirectoryOutput, CloseFunc) {
+	return setupDirectoryOutput(dir, layout, false, true)
+}
+
+func setupDirectoryOutput(dir string, layout Layout, overwriteExisting, merge bool) (*DirectoryOutput, CloseFunc) {
+	if dir == "" || dir == "-" {
+		log.Fatal().Str("layout", string(layout)).Msg("output layout requires an output directory")
+	}
+
+	if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+		log.Fatal().Str("dir", dir).Msg("output path exists and is not a directory")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+
+	d := &DirectoryOutput{
+		dir:               dir,
+		layout:            layout,
+		overwriteExisting: overwriteExisting,
+		merge:             merge,
+		files:             map[string]io.Writer{},
+	}
+
+	return d, d.close
+}
+
+// Writer returns the io.Writer for the file the given object belongs in
+func (d *DirectoryOutput) Writer(obj runtime.Object) io.Writer {
+	name := FileName(d.layout, obj)
+	if d.Extension != "" {
+		name = strings.TrimSuffix(name, ".tf") + d.Extension
+	}
+
+	return d.File(name)
+}
+
+// File returns the io.Writer for the named file in the output directory, e.g. variables.tf
+func (d *DirectoryOutput) File(name string) io.Writer {
+	name = filepath.Join(d.dir, name)
+	if w, ok := d.files[name]; ok {
+		return w
+	}
+
+	var w io.Writer
+	var closeFn CloseFunc
+	if d.merge {
+		w, closeFn = SetupMergeOutput(name)
+	} else {
+		w, closeFn = SetupOutput(name, d.overwriteExisting)
+	}
+	d.files[name] = w
+	d.closers = append(d.closers, closeFn)
+
+	return w
+}
+
+func (d *DirectoryOutput) close() {
+	for _, closeFn := range d.closers {
+		closeFn()
+	}
+}
+
+// FileName returns the name of the output file that obj belongs in for the given layout.
+func FileName(layout Layout, obj runtime.Object) string {
+	var name string
+
+	resourceType := tfkschema.ToTerraformResourceType(obj)
+	supported := tfkschema.IsKubernetesKindSupported(obj)
+
+	switch layout {
+	case LayoutNamespace:
+		ns := k8sutils.ObjectMeta(obj).Namespace
+		switch {
+		case ns != "":
+			name = tfkschema.NormalizeTerraformName(ns, false, "")
+		case supported && !tfkschema.IsAttributeSupported(resourceType+".metadata.namespace"):
+			name = clusterScopedFileName
+		default:
+			name = defaultNamespaceFileName
+		}
+
+	case LayoutType:
+		if supported {
+			name = resourceType
+		} else {
+			name = manifestResourceType
+		}
+
+	default:
+		if supported {
+			name = resourceType + "_" + tfkschema.ToTerraformResourceName(obj)
+		} else {
+			kind := tfkschema.NormalizeTerraformName(k8sutils.TypeMeta(obj).Kind, false, "")
+			name = manifestResourceType + "_" + kind + "_" + tfkschema.ToTerraformResourceName(obj)
+		}
+	}
+
+	return name + ".tf"
+}
+
+func openOutputFile(name string, overwriteExisting bool) *os.File {
+	if _, err := os.Stat(name); err == nil && !overwriteExisting {
+		// don't clobber
+		log.Fatal().Str("file", name).Msg("output file already exists")
+	}
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	log.Debug().Str("file", name).Msg("opened file")
+
+	return f
+}
+
+func closeOutputFile(f *os.File) {
+	if err := f.Close(); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	log.Debug().Str("file", f.Name()).Msg("closed output file")
+}
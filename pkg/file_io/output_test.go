@@ -0,0 +1,124 @@
+package file_io
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout Layout
+		yaml   string
+		want   string
+	}{
+		{
+			"object",
+			LayoutObject,
+			"../../test-fixtures/service.yaml",
+			"kubernetes_service_nginx.tf",
+		},
+		{
+			"object/manifest",
+			LayoutObject,
+			"../../test-fixtures/manifest/cronTab.yaml",
+			"kubernetes_manifest_cron_tab_my_new_cron_object.tf",
+		},
+		{
+			"namespace",
+			LayoutNamespace,
+			"../../test-fixtures/configMap.yaml",
+			"bar.tf",
+		},
+		{
+			"namespace/cluster_scoped",
+			LayoutNamespace,
+			"../../test-fixtures/clusterRole.yaml",
+			"cluster.tf",
+		},
+		{
+			"namespace/default",
+			LayoutNamespace,
+			"../../test-fixtures/service.yaml",
+			"default.tf",
+		},
+		{
+			"type",
+			LayoutType,
+			"../../test-fixtures/service.yaml",
+			"kubernetes_service.tf",
+		},
+		{
+			"type/manifest",
+			LayoutType,
+			"../../test-fixtures/manifest/cronTab.yaml",
+			"kubernetes_manifest.tf",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := os.ReadFile(tt.yaml)
+			if err != nil {
+				t.Fatal(err)
+			}
+			obj := testutils.TestParseYAML(t, string(content))
+
+			assert.Equal(t, tt.want, FileName(tt.layout, obj))
+		})
+	}
+}
+
+func TestDirectoryOutput_Writer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	d, closer := SetupDirectoryOutput(dir, LayoutType, false)
+
+	objs := readFilesInput("../../test-fixtures/deployment.yaml", InputOptions{}).Objects
+	objs = append(objs, readFilesInput("../../test-fixtures/basicDeployment.yaml", InputOptions{}).Objects...)
+	for _, obj := range objs {
+		d.Writer(obj).Write([]byte("# " + FileName(LayoutType, obj) + "\n"))
+	}
+	closer()
+
+	content, err := os.ReadFile(filepath.Join(dir, "kubernetes_deployment.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "# kubernetes_deployment.tf\n# kubernetes_deployment.tf\n", string(content))
+}
+
+func TestSetupMergeOutput(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "main.tf")
+	existing := `resource "kubernetes_namespace" "web" {
+  metadata {
+    name = "web"
+    # managed by team-a
+    labels = local.labels
+  }
+}
+`
+	if err := os.WriteFile(name, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, closer := SetupMergeOutput(name)
+	w.Write([]byte(`resource "kubernetes_namespace" "web" {
+  metadata {
+    name = "web"
+    labels = {
+      app = "web"
+    }
+  }
+}
+`))
+	closer()
+
+	content, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, existing, string(content))
+}
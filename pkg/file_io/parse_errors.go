@@ -0,0 +1,13 @@
+package file_io
+
+// ParseError describes input that could not be parsed as Kubernetes API Objects
+type ParseError struct {
+	// Source is the location of the input, e.g. a file name or Helm chart template
+	Source string `json:"source"`
+	// Error is the parser error message
+	Error string `json:"error"`
+}
+
+func (in *Input) recordParseError(source string, err error) {
+	in.ParseErrors = append(in.ParseErrors, ParseError{Source: source, Error: err.Error()})
+}
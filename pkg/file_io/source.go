@@ -0,0 +1,35 @@
+package file_io
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Input holds the Kubernetes objects read by ReadInput, the location each object was read from, and the
+// errors of input that could not be parsed
+type Input struct {
+	// Objects read from the input, in order
+	Objects []runtime.Object
+	// ParseErrors holds the errors of input that could not be parsed. Objects that could be parsed from the
+	// same input are still part of Objects.
+	ParseErrors []ParseError
+
+	// sources maps each object to the location it was read from
+	sources map[runtime.Object]string
+}
+
+// add appends objects read from source
+func (in *Input) add(objs []runtime.Object, source string) {
+	if in.sources == nil {
+		in.sources = map[runtime.Object]string{}
+	}
+	for _, obj := range objs {
+		in.sources[obj] = source
+	}
+	in.Objects = append(in.Objects, objs...)
+}
+
+// Source returns the location the given object was read from, e.g. the Helm chart template
+// it was rendered from. An empty string is returned if the location is unknown.
+func (in *Input) Source(obj runtime.Object) string {
+	return in.sources[obj]
+}
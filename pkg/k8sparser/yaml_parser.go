@@ -7,6 +7,7 @@ import (
 
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/rs/zerolog/log"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -33,21 +34,31 @@ func ParseYAML(in io.Reader) ([]runtime.Object, error) {
 		// First try main decoder
 		d := scheme.Codecs.UniversalDeserializer()
 		obj, _, err := d.Decode(doc, nil, nil)
-		if err != nil {
-			log.Error().Err(err)
-			wrapped := fmt.Errorf("could not decode yaml object with main scheme #%d: %v", i, err)
+		if err != nil && runtime.IsNotRegisteredError(err) {
+			log.Debug().Err(err).Msg("kind not registered with main scheme")
 
 			// Fallback on aggregator decoder
 			d = aggregator_scheme.Codecs.UniversalDeserializer()
 			obj, _, err = d.Decode(doc, nil, nil)
-			if err != nil {
-				log.Error().Err(err)
+			if err != nil && runtime.IsNotRegisteredError(err) {
+				log.Debug().Err(err).Msg("kind not registered with aggregator scheme")
 
-				// Push both errors
-				result = multierror.Append(result, wrapped)
-				wrapped = fmt.Errorf("could not decode yaml object with aggregator scheme #%d: %v", i, err)
-				result = multierror.Append(result, wrapped)
+				// Last resort, decode kinds unknown to our schemes (e.g. Custom Resources) as unstructured objects
+				obj, err = parseUnstructured(doc)
+				if err != nil {
+					err = fmt.Errorf("could not decode yaml object as unstructured #%d: %v", i, err)
+				}
+			} else if err != nil {
+				err = fmt.Errorf("could not decode yaml object with aggregator scheme #%d: %v", i, err)
 			}
+		} else if err != nil {
+			// a known kind that's invalid, e.g. a field with the wrong type, must not be passed on as unstructured
+			err = fmt.Errorf("could not decode yaml object with main scheme #%d: %v", i, err)
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			result = multierror.Append(result, err)
+			obj = nil
 		}
 
 		if obj != nil {
@@ -58,6 +69,22 @@ func ParseYAML(in io.Reader) ([]runtime.Object, error) {
 	return objs, result
 }
 
+// parseUnstructured decodes a YAML document that is not known to any of our schemes
+// into a generic unstructured.Unstructured object.
+func parseUnstructured(doc []byte) (runtime.Object, error) {
+	j, err := yaml.ToJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(j); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
 func ParseJSON(doc []byte) (runtime.Object, error) {
 	var result error
 
@@ -2,11 +2,22 @@ package k8sutils
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"reflect"
 )
 
 func ObjectMeta(obj runtime.Object) metav1.ObjectMeta {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		// unstructured objects (e.g. Custom Resources) hold their metadata in a generic map
+		return metav1.ObjectMeta{
+			Name:        u.GetName(),
+			Namespace:   u.GetNamespace(),
+			Labels:      u.GetLabels(),
+			Annotations: u.GetAnnotations(),
+		}
+	}
+
 	v := reflect.ValueOf(obj)
 
 	if v.Kind() == reflect.Ptr {
@@ -19,6 +30,14 @@ func ObjectMeta(obj runtime.Object) metav1.ObjectMeta {
 }
 
 func TypeMeta(obj runtime.Object) metav1.TypeMeta {
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		apiVersion, kind := obj.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
+		return metav1.TypeMeta{
+			APIVersion: apiVersion,
+			Kind:       kind,
+		}
+	}
+
 	v := reflect.ValueOf(obj)
 
 	if v.Kind() == reflect.Ptr {
@@ -30,3 +49,9 @@ func TypeMeta(obj runtime.Object) metav1.TypeMeta {
 	return metaF.Interface().(metav1.TypeMeta)
 }
 
+// IsUnstructured returns true if the object could not be decoded into one of the
+// typed Kubernetes API structs, and is instead held in a generic map.
+func IsUnstructured(obj runtime.Object) bool {
+	_, ok := obj.(*unstructured.Unstructured)
+	return ok
+}
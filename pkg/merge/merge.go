@@ -0,0 +1,254 @@
+// Package merge updates existing Terraform config with newly generated config, preserving manual edits.
+package merge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/rs/zerolog/log"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Stats counts the changes made by Merge
+type Stats struct {
+	// Added is the number of blocks that were not found in the existing config, and were appended
+	Added int
+	// Updated is the number of attributes that were added or updated
+	Updated int
+	// Kept is the number of attributes that differ from the generated config, but were kept because their
+	// existing value is an expression (e.g. a reference or a function call) rather than a literal value
+	Kept int
+}
+
+// Merge merges generated config into existing config, and returns the updated existing config.
+//
+// Blocks are matched by type and labels, and repeated nested blocks (e.g. container) by their name attribute or
+// their position. Attributes with a literal value that differs from the generated config are updated, and missing
+// attributes and blocks are added. Attributes whose value is an expression, comments, and attributes and blocks
+// that are not part of the generated config (e.g. lifecycle blocks) are left untouched.
+func Merge(existing, generated []byte, filename string) ([]byte, Stats, error) {
+	var stats Stats
+
+	dst, diags := hclwrite.ParseConfig(existing, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, stats, fmt.Errorf("could not parse existing config: %w", diags)
+	}
+
+	src, diags := hclwrite.ParseConfig(generated, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, stats, fmt.Errorf("could not parse generated config: %w", diags)
+	}
+
+	// hclwrite doesn't expose the order of attributes, so the generated config is parsed again to find it
+	srcSyntax, diags := hclsyntax.ParseConfig(generated, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, stats, fmt.Errorf("could not parse generated config: %w", diags)
+	}
+
+	m := &merger{stats: &stats}
+	m.mergeBody(dst.Body(), src.Body(), srcSyntax.Body.(*hclsyntax.Body), "")
+
+	return hclwrite.Format(dst.Bytes()), stats, nil
+}
+
+type merger struct {
+	stats *Stats
+}
+
+func (m *merger) mergeBody(dst, src *hclwrite.Body, srcSyntax *hclsyntax.Body, path string) {
+	for _, name := range attributeNames(srcSyntax) {
+		m.mergeAttribute(dst, name, src.GetAttribute(name).Expr().BuildTokens(nil), path)
+	}
+
+	dstBlocks := dst.Blocks()
+	matched := map[*hclwrite.Block]bool{}
+	for i, s := range src.Blocks() {
+		d := matchBlock(dstBlocks, s, src.Blocks(), matched, path == "")
+		if d == nil {
+			if path == "" && len(dst.Blocks()) > 0 {
+				// separate top level blocks
+				dst.AppendNewline()
+			}
+			dst.AppendUnstructuredTokens(s.BuildTokens(nil))
+			m.stats.Added++
+			log.Debug().Msgf("adding block %s", joinPath(path, blockName(s)))
+			continue
+		}
+
+		matched[d] = true
+		m.mergeBody(d.Body(), s.Body(), srcSyntax.Blocks[i].Body, joinPath(path, blockName(s)))
+	}
+}
+
+func (m *merger) mergeAttribute(dst *hclwrite.Body, name string, srcTokens hclwrite.Tokens, path string) {
+	attrPath := joinPath(path, name)
+
+	existing := dst.GetAttribute(name)
+	if existing == nil {
+		dst.SetAttributeRaw(name, srcTokens)
+		m.stats.Updated++
+		log.Debug().Msgf("adding attribute %s", attrPath)
+		return
+	}
+
+	dstTokens := existing.Expr().BuildTokens(nil)
+	if string(dstTokens.Bytes()) == string(srcTokens.Bytes()) {
+		return
+	}
+
+	dstVal, ok := literalValue(dstTokens)
+	if !ok {
+		m.stats.Kept++
+		log.Debug().Msgf("keeping expression of attribute %s", attrPath)
+		return
+	}
+	if srcVal, ok := literalValue(srcTokens); ok && dstVal.RawEquals(srcVal) {
+		// same value, formatted differently
+		return
+	}
+
+	dst.SetAttributeRaw(name, srcTokens)
+	m.stats.Updated++
+	log.Debug().Msgf("updating attribute %s", attrPath)
+}
+
+// matchBlock returns the block of candidates that src should be merged into.
+// Blocks must have the same type and labels. At the top level, import blocks are matched by their to
+// argument, and locals blocks by the names of their local values. Otherwise, when src has a literal name
+// attribute, the block with the same name is preferred, or else the first block that doesn't share its name
+// with any of srcBlocks.
+func matchBlock(candidates []*hclwrite.Block, src *hclwrite.Block, srcBlocks []*hclwrite.Block, matched map[*hclwrite.Block]bool, topLevel bool) *hclwrite.Block {
+	var sameType []*hclwrite.Block
+	for _, c := range candidates {
+		if !matched[c] && c.Type() == src.Type() && equalLabels(c.Labels(), src.Labels()) {
+			sameType = append(sameType, c)
+		}
+	}
+
+	if topLevel {
+		switch src.Type() {
+		case "import":
+			// the to argument is a reference, that isn't updated by mergeAttribute
+			to := attributeSource(src, "to")
+			for _, c := range sameType {
+				if to != "" && attributeSource(c, "to") == to {
+					return c
+				}
+			}
+			return nil
+
+		case "locals":
+			for _, c := range sameType {
+				for name := range src.Body().Attributes() {
+					if c.Body().GetAttribute(name) != nil {
+						return c
+					}
+				}
+			}
+			return nil
+		}
+	}
+
+	if name, ok := blockNameAttr(src); ok {
+		for _, c := range sameType {
+			if n, ok := blockNameAttr(c); ok && n == name {
+				return c
+			}
+		}
+	}
+
+	srcNames := map[string]bool{}
+	for _, s := range srcBlocks {
+		if n, ok := blockNameAttr(s); ok && s.Type() == src.Type() {
+			srcNames[n] = true
+		}
+	}
+	for _, c := range sameType {
+		if n, ok := blockNameAttr(c); !ok || !srcNames[n] {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// blockNameAttr returns the literal value of the name attribute of b, e.g. the name of a container block
+func blockNameAttr(b *hclwrite.Block) (string, bool) {
+	attr := b.Body().GetAttribute("name")
+	if attr == nil {
+		return "", false
+	}
+
+	v, ok := literalValue(attr.Expr().BuildTokens(nil))
+	if !ok || v.Type() != cty.String || v.IsNull() {
+		return "", false
+	}
+	return v.AsString(), true
+}
+
+// attributeSource returns the source of the named attribute of b without whitespace, e.g.
+// kubernetes_config_map.settings, or an empty string if it's not set
+func attributeSource(b *hclwrite.Block, name string) string {
+	attr := b.Body().GetAttribute(name)
+	if attr == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(string(attr.Expr().BuildTokens(nil).Bytes())), "")
+}
+
+// literalValue returns the value of an expression that doesn't reference variables or call functions
+func literalValue(tokens hclwrite.Tokens) (cty.Value, bool) {
+	expr, diags := hclsyntax.ParseExpression(tokens.Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, false
+	}
+
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() || !v.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+	return v, true
+}
+
+// attributeNames returns the names of the attributes of body, in source order
+func attributeNames(body *hclsyntax.Body) []string {
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return body.Attributes[names[i]].SrcRange.Start.Byte < body.Attributes[names[j]].SrcRange.Start.Byte
+	})
+	return names
+}
+
+func equalLabels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func blockName(b *hclwrite.Block) string {
+	name := b.Type()
+	for _, l := range b.Labels() {
+		name += "." + l
+	}
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,370 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  string
+		generated string
+		want      string
+		wantStats Stats
+	}{
+		{
+			"update literal",
+			`resource "kubernetes_config_map" "settings" {
+  metadata {
+    name = "settings"
+  }
+  data = {
+    level = "info"
+  }
+}
+`,
+			`resource "kubernetes_config_map" "settings" {
+  metadata {
+    name = "settings"
+  }
+  data = {
+    level = "debug"
+  }
+}
+`,
+			`resource "kubernetes_config_map" "settings" {
+  metadata {
+    name = "settings"
+  }
+  data = {
+    level = "debug"
+  }
+}
+`,
+			Stats{Updated: 1},
+		},
+		{
+			"keep manual edits",
+			`# managed by team-a
+resource "kubernetes_deployment" "web" {
+  metadata {
+    # keep in sync with the service
+    name      = "web"
+    namespace = kubernetes_namespace.web.metadata[0].name
+  }
+  spec {
+    replicas = var.replicas
+  }
+
+  lifecycle {
+    ignore_changes = [spec[0].replicas]
+  }
+}
+
+resource "kubernetes_namespace" "web" {
+  metadata {
+    name = "web"
+  }
+}
+`,
+			`resource "kubernetes_deployment" "web" {
+  metadata {
+    name      = "web"
+    namespace = "web"
+    labels = {
+      app = "web"
+    }
+  }
+  spec {
+    replicas = 3
+  }
+}
+`,
+			`# managed by team-a
+resource "kubernetes_deployment" "web" {
+  metadata {
+    # keep in sync with the service
+    name      = "web"
+    namespace = kubernetes_namespace.web.metadata[0].name
+    labels = {
+      app = "web"
+    }
+  }
+  spec {
+    replicas = var.replicas
+  }
+
+  lifecycle {
+    ignore_changes = [spec[0].replicas]
+  }
+}
+
+resource "kubernetes_namespace" "web" {
+  metadata {
+    name = "web"
+  }
+}
+`,
+			Stats{Updated: 1, Kept: 2},
+		},
+		{
+			"same value formatted differently",
+			`resource "kubernetes_service" "web" {
+  spec {
+    port {
+      port = 80
+    }
+    selector = { "app" = "web" }
+  }
+}
+`,
+			`resource "kubernetes_service" "web" {
+  spec {
+    port {
+      port = 80
+    }
+    selector = {
+      app = "web"
+    }
+  }
+}
+`,
+			`resource "kubernetes_service" "web" {
+  spec {
+    port {
+      port = 80
+    }
+    selector = { "app" = "web" }
+  }
+}
+`,
+			Stats{},
+		},
+		{
+			"match blocks by name",
+			`resource "kubernetes_pod" "web" {
+  spec {
+    container {
+      name  = "sidecar"
+      image = "envoy:1.0"
+    }
+    container {
+      name  = "web"
+      image = "web:1.0"
+    }
+  }
+}
+`,
+			`resource "kubernetes_pod" "web" {
+  spec {
+    container {
+      name  = "web"
+      image = "web:2.0"
+    }
+    container {
+      name  = "sidecar"
+      image = "envoy:1.0"
+    }
+    container {
+      name  = "metrics"
+      image = "exporter:1.0"
+    }
+  }
+}
+`,
+			`resource "kubernetes_pod" "web" {
+  spec {
+    container {
+      name  = "sidecar"
+      image = "envoy:1.0"
+    }
+    container {
+      name  = "web"
+      image = "web:2.0"
+    }
+    container {
+      name  = "metrics"
+      image = "exporter:1.0"
+    }
+  }
+}
+`,
+			Stats{Added: 1, Updated: 1},
+		},
+		{
+			"append new resources",
+			`resource "kubernetes_namespace" "web" {
+  metadata {
+    name = "web"
+  }
+}
+`,
+			`resource "kubernetes_namespace" "web" {
+  metadata {
+    name = "web"
+  }
+}
+
+resource "kubernetes_namespace" "api" {
+  metadata {
+    name = "api"
+  }
+}
+`,
+			`resource "kubernetes_namespace" "web" {
+  metadata {
+    name = "web"
+  }
+}
+
+resource "kubernetes_namespace" "api" {
+  metadata {
+    name = "api"
+  }
+}
+`,
+			Stats{Added: 1},
+		},
+		{
+			"import blocks matched by address",
+			`resource "kubernetes_config_map" "a" {
+  metadata {
+    name = "a"
+  }
+}
+
+import {
+  to = kubernetes_config_map.a
+  id = "ns/a"
+}
+
+resource "kubernetes_config_map" "b" {
+  metadata {
+    name = "b"
+  }
+}
+
+import {
+  to = kubernetes_config_map.b
+  id = "ns/b"
+}
+`,
+			`resource "kubernetes_config_map" "a" {
+  metadata {
+    name = "a"
+  }
+}
+
+import {
+  to = kubernetes_config_map.a
+  id = "ns/a"
+}
+
+resource "kubernetes_config_map" "c" {
+  metadata {
+    name = "c"
+  }
+}
+
+import {
+  to = kubernetes_config_map.c
+  id = "ns/c"
+}
+
+resource "kubernetes_config_map" "b" {
+  metadata {
+    name = "b"
+  }
+}
+
+import {
+  to = kubernetes_config_map.b
+  id = "ns/b"
+}
+`,
+			`resource "kubernetes_config_map" "a" {
+  metadata {
+    name = "a"
+  }
+}
+
+import {
+  to = kubernetes_config_map.a
+  id = "ns/a"
+}
+
+resource "kubernetes_config_map" "b" {
+  metadata {
+    name = "b"
+  }
+}
+
+import {
+  to = kubernetes_config_map.b
+  id = "ns/b"
+}
+
+resource "kubernetes_config_map" "c" {
+  metadata {
+    name = "c"
+  }
+}
+
+import {
+  to = kubernetes_config_map.c
+  id = "ns/c"
+}
+`,
+			Stats{Added: 2},
+		},
+		{
+			"locals blocks matched by name",
+			`locals {
+  namespace = "shop"
+}
+
+locals {
+  replicas = 2
+}
+`,
+			`locals {
+  replicas = 3
+}
+
+locals {
+  image = "nginx"
+}
+`,
+			`locals {
+  namespace = "shop"
+}
+
+locals {
+  replicas = 3
+}
+
+locals {
+  image = "nginx"
+}
+`,
+			Stats{Added: 1, Updated: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, stats, err := Merge([]byte(tt.existing), []byte(tt.generated), "main.tf")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.wantStats, stats)
+		})
+	}
+}
+
+func TestMerge_invalidExisting(t *testing.T) {
+	_, _, err := Merge([]byte(`resource "kubernetes_namespace" {`), []byte(""), "main.tf")
+	assert.Error(t, err)
+}
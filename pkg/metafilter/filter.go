@@ -0,0 +1,328 @@
+// Package metafilter removes metadata added by kubectl, controllers and the API server (e.g. managedFields,
+// the last-applied-configuration annotation or pod-template-hash labels) from Kubernetes API Objects, so that
+// it's not written to the generated Terraform config.
+package metafilter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/rs/zerolog/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Target is the part of the object metadata a Rule applies to
+type Target string
+
+const (
+	// Annotation rules match annotation keys
+	Annotation Target = "annotation"
+	// Label rules match label keys
+	Label Target = "label"
+	// Field rules match the JSON names of metadata fields, e.g. managedFields
+	Field Target = "field"
+)
+
+// DefaultRules are the built-in rules, removing metadata that is managed by kubectl, controllers or the API server
+var DefaultRules = []string{
+	"field:managedFields",
+	"annotation:kubectl.kubernetes.io/last-applied-configuration",
+	"annotation:kubectl.kubernetes.io/restartedAt",
+	"annotation:deployment.kubernetes.io/*",
+	"annotation:deprecated.daemonset.template.generation",
+	"annotation:endpoints.kubernetes.io/*",
+	"annotation:pv.kubernetes.io/*",
+	"annotation:volume.kubernetes.io/*",
+	"annotation:volume.beta.kubernetes.io/storage-provisioner",
+	"annotation:control-plane.alpha.kubernetes.io/leader",
+	"annotation:kubernetes.io/service-account.uid",
+	"label:pod-template-hash",
+	"label:controller-revision-hash",
+	"label:statefulset.kubernetes.io/pod-name",
+	"label:kubernetes.io/metadata.name",
+}
+
+// Rule removes annotations, labels or metadata fields whose key matches Pattern, or keeps them when Keep is set
+type Rule struct {
+	Target Target
+	// Pattern is a glob pattern, e.g. "pv.kubernetes.io/*"
+	Pattern string
+	// Keep overrides earlier rules matching the same keys
+	Keep bool
+
+	glob glob.Glob
+}
+
+// ParseRule parses a rule in the form "<target>:<pattern>", e.g. "annotation:pv.kubernetes.io/*".
+// Rules prefixed with "!" keep the matching keys, overriding earlier rules, e.g. "!label:pod-template-hash".
+func ParseRule(s string) (Rule, error) {
+	r := Rule{}
+	if strings.HasPrefix(s, "!") {
+		r.Keep = true
+		s = s[1:]
+	}
+
+	target, pattern, ok := strings.Cut(s, ":")
+	if !ok || pattern == "" {
+		return r, fmt.Errorf("invalid metadata filter rule %q, expected <target>:<pattern>", s)
+	}
+
+	r.Target = Target(target)
+	switch r.Target {
+	case Annotation, Label, Field:
+	default:
+		return r, fmt.Errorf("invalid metadata filter rule %q, target must be one of annotation, label or field", s)
+	}
+
+	g, err := glob.Compile(pattern)
+	if err != nil {
+		return r, fmt.Errorf("invalid metadata filter rule %q: %w", s, err)
+	}
+	r.Pattern = pattern
+	r.glob = g
+
+	return r, nil
+}
+
+// Filter removes the metadata matched by its rules. When several rules match a key, the last one wins.
+type Filter struct {
+	// Selectors removes the labels matched by the rules from label selectors too. Otherwise, labels used by the
+	// selectors of an object are kept in its templates, so that the selectors still match them.
+	// Removing a label from a selector changes the objects it matches, e.g. the pods of other ReplicaSets.
+	Selectors bool
+
+	rules []Rule
+}
+
+// New returns a Filter with the given rules
+func New(rules ...Rule) *Filter {
+	return &Filter{rules: rules}
+}
+
+// Parse returns a Filter with the given rules, in the format accepted by ParseRule.
+// The DefaultRules are prepended when withDefaults is set, so that the given rules can override them.
+func Parse(rules []string, withDefaults bool) (*Filter, error) {
+	if withDefaults {
+		rules = append(append([]string{}, DefaultRules...), rules...)
+	}
+
+	f := &Filter{}
+	for _, s := range rules {
+		r, err := ParseRule(s)
+		if err != nil {
+			return nil, err
+		}
+		f.rules = append(f.rules, r)
+	}
+
+	return f, nil
+}
+
+// Apply removes the matching metadata from obj, in place. The metadata of embedded templates (e.g. the pod template
+// of a Deployment) is filtered too. Labels used by label selectors are kept, unless Selectors is set, in which case
+// they're removed from the selectors too, so that selectors still match the filtered templates.
+// Only the top level metadata of unstructured objects is filtered.
+// The paths of the removed keys are returned, e.g. metadata.annotations["deployment.kubernetes.io/revision"].
+func (f *Filter) Apply(obj runtime.Object) []string {
+	if f == nil || len(f.rules) == 0 {
+		return nil
+	}
+
+	var removed []string
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		f.applyUnstructured(u.Object, &removed)
+	} else {
+		var selected map[string]bool
+		if !f.Selectors {
+			selected = map[string]bool{}
+			selectorLabels(reflect.ValueOf(obj), selected)
+		}
+		f.walk(reflect.ValueOf(obj), "", selected, &removed)
+	}
+
+	for _, path := range removed {
+		log.Debug().Str("kind", obj.GetObjectKind().GroupVersionKind().Kind).Msgf("removing %s", path)
+	}
+
+	return removed
+}
+
+// remove returns true if the rules remove the key of target
+func (f *Filter) remove(target Target, key string) bool {
+	remove := false
+	for _, r := range f.rules {
+		if r.Target == target && r.glob.Match(key) {
+			remove = !r.Keep
+		}
+	}
+	return remove
+}
+
+// filterMap removes the keys of m matched by the rules, except the keys in keep
+func (f *Filter) filterMap(m map[string]string, target Target, path string, keep map[string]bool, removed *[]string) {
+	for k := range m {
+		if !keep[k] && f.remove(target, k) {
+			delete(m, k)
+			*removed = append(*removed, fmt.Sprintf("%s[%q]", path, k))
+		}
+	}
+}
+
+var (
+	objectMetaType    = reflect.TypeOf(metav1.ObjectMeta{})
+	labelSelectorType = reflect.TypeOf(metav1.LabelSelector{})
+)
+
+// walk filters every ObjectMeta reachable from v, and every LabelSelector when selected is nil.
+// Labels in selected are kept in templates.
+func (f *Filter) walk(v reflect.Value, path string, selected map[string]bool, removed *[]string) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			f.walk(v.Elem(), path, selected, removed)
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			f.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), selected, removed)
+		}
+
+	case reflect.Struct:
+		if !v.CanAddr() {
+			return
+		}
+
+		switch v.Type() {
+		case objectMetaType:
+			f.filterObjectMeta(v.Addr().Interface().(*metav1.ObjectMeta), path, selected, removed)
+			return
+		case labelSelectorType:
+			if selected == nil {
+				f.filterMap(v.Addr().Interface().(*metav1.LabelSelector).MatchLabels, Label, joinPath(path, "matchLabels"), nil, removed)
+			}
+			return
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			f.walk(v.Field(i), joinPath(path, jsonName(field)), selected, removed)
+		}
+	}
+}
+
+func (f *Filter) filterObjectMeta(meta *metav1.ObjectMeta, path string, selected map[string]bool, removed *[]string) {
+	f.filterMap(meta.Annotations, Annotation, joinPath(path, "annotations"), nil, removed)
+	if path == "metadata" {
+		// the labels of the object itself are not matched by its selectors
+		selected = nil
+	}
+	f.filterMap(meta.Labels, Label, joinPath(path, "labels"), selected, removed)
+
+	v := reflect.ValueOf(meta).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := jsonName(field)
+		if name == "" || name == "annotations" || name == "labels" || v.Field(i).IsZero() {
+			continue
+		}
+		if f.remove(Field, name) {
+			v.Field(i).Set(reflect.Zero(field.Type))
+			*removed = append(*removed, joinPath(path, name))
+		}
+	}
+}
+
+// selectorLabels records the keys of the labels used by the label selectors reachable from v in keys: the match
+// labels and expressions of LabelSelectors, and the selector maps of e.g. ReplicationControllers
+func selectorLabels(v reflect.Value, keys map[string]bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			selectorLabels(v.Elem(), keys)
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			selectorLabels(v.Index(i), keys)
+		}
+
+	case reflect.Struct:
+		if v.Type() == labelSelectorType {
+			sel := v.Interface().(metav1.LabelSelector)
+			for k := range sel.MatchLabels {
+				keys[k] = true
+			}
+			for _, e := range sel.MatchExpressions {
+				keys[e.Key] = true
+			}
+			return
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if m, ok := v.Field(i).Interface().(map[string]string); ok && jsonName(field) == "selector" {
+				for k := range m {
+					keys[k] = true
+				}
+				continue
+			}
+			selectorLabels(v.Field(i), keys)
+		}
+	}
+}
+
+// applyUnstructured filters the top level metadata of an unstructured object
+func (f *Filter) applyUnstructured(obj map[string]interface{}, removed *[]string) {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for name, val := range metadata {
+		switch name {
+		case "annotations", "labels":
+			target := Annotation
+			if name == "labels" {
+				target = Label
+			}
+			m, _ := val.(map[string]interface{})
+			for k := range m {
+				if f.remove(target, k) {
+					delete(m, k)
+					*removed = append(*removed, fmt.Sprintf("metadata.%s[%q]", name, k))
+				}
+			}
+		default:
+			if f.remove(Field, name) {
+				delete(metadata, name)
+				*removed = append(*removed, "metadata."+name)
+			}
+		}
+	}
+}
+
+// jsonName returns the JSON name of a struct field, or an empty string for inlined fields
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" || name == "" {
+		return path + name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,169 @@
+package metafilter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+  annotations:
+    deployment.kubernetes.io/revision: "3"
+    kubectl.kubernetes.io/last-applied-configuration: |
+      {"apiVersion":"apps/v1","kind":"Deployment"}
+    team: platform
+  managedFields:
+  - manager: kubectl
+    operation: Update
+spec:
+  selector:
+    matchLabels:
+      app: web
+      pod-template-hash: 5d59d67564
+  template:
+    metadata:
+      labels:
+        app: web
+        pod-template-hash: 5d59d67564
+      annotations:
+        kubectl.kubernetes.io/restartedAt: "2024-03-01T10:00:00Z"
+    spec:
+      containers:
+      - name: web
+        image: nginx
+`
+
+func TestFilter_Apply(t *testing.T) {
+	objs, err := k8sparser.ParseYAML(strings.NewReader(deployment))
+	require.NoError(t, err)
+
+	f, err := Parse(nil, true)
+	require.NoError(t, err)
+	f.Selectors = true
+
+	removed := f.Apply(objs[0])
+	assert.ElementsMatch(t, []string{
+		`metadata.annotations["deployment.kubernetes.io/revision"]`,
+		`metadata.annotations["kubectl.kubernetes.io/last-applied-configuration"]`,
+		"metadata.managedFields",
+		`spec.selector.matchLabels["pod-template-hash"]`,
+		`spec.template.metadata.annotations["kubectl.kubernetes.io/restartedAt"]`,
+		`spec.template.metadata.labels["pod-template-hash"]`,
+	}, removed)
+
+	d := objs[0].(*appsv1.Deployment)
+	assert.Equal(t, map[string]string{"team": "platform"}, d.Annotations)
+	assert.Equal(t, map[string]string{"app": "web"}, d.Labels)
+	assert.Empty(t, d.ManagedFields)
+	assert.Equal(t, map[string]string{"app": "web"}, d.Spec.Selector.MatchLabels)
+	assert.Equal(t, map[string]string{"app": "web"}, d.Spec.Template.Labels)
+	assert.Empty(t, d.Spec.Template.Annotations)
+}
+
+func TestFilter_Apply_userRules(t *testing.T) {
+	objs, err := k8sparser.ParseYAML(strings.NewReader(deployment))
+	require.NoError(t, err)
+
+	f, err := Parse([]string{"annotation:team", "!annotation:deployment.kubernetes.io/revision", "label:app"}, true)
+	require.NoError(t, err)
+	f.Selectors = true
+	f.Apply(objs[0])
+
+	d := objs[0].(*appsv1.Deployment)
+	assert.Equal(t, map[string]string{"deployment.kubernetes.io/revision": "3"}, d.Annotations)
+	assert.Empty(t, d.Labels)
+	assert.Empty(t, d.Spec.Selector.MatchLabels)
+}
+
+func TestFilter_Apply_keepSelectors(t *testing.T) {
+	objs, err := k8sparser.ParseYAML(strings.NewReader(deployment))
+	require.NoError(t, err)
+
+	f, err := Parse([]string{"label:app"}, true)
+	require.NoError(t, err)
+
+	removed := f.Apply(objs[0])
+	assert.Contains(t, removed, `metadata.labels["app"]`)
+	assert.NotContains(t, removed, `spec.selector.matchLabels["pod-template-hash"]`)
+	assert.NotContains(t, removed, `spec.template.metadata.labels["pod-template-hash"]`)
+
+	// the selector is not rewritten, and still matches the template
+	d := objs[0].(*appsv1.Deployment)
+	assert.Empty(t, d.Labels)
+	assert.Equal(t, map[string]string{"app": "web", "pod-template-hash": "5d59d67564"}, d.Spec.Selector.MatchLabels)
+	assert.Equal(t, map[string]string{"app": "web", "pod-template-hash": "5d59d67564"}, d.Spec.Template.Labels)
+}
+
+func TestFilter_Apply_withoutDefaults(t *testing.T) {
+	objs, err := k8sparser.ParseYAML(strings.NewReader(deployment))
+	require.NoError(t, err)
+
+	f, err := Parse([]string{"annotation:team"}, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`metadata.annotations["team"]`}, f.Apply(objs[0]))
+}
+
+func TestFilter_Apply_unstructured(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name":          "gizmo",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"team": "platform",
+			},
+		},
+	}}
+
+	f, err := Parse(nil, true)
+	require.NoError(t, err)
+	f.Apply(u)
+
+	assert.Equal(t, map[string]interface{}{
+		"name":        "gizmo",
+		"annotations": map[string]interface{}{"team": "platform"},
+	}, u.Object["metadata"])
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    Rule
+		wantErr bool
+	}{
+		{"annotation:pv.kubernetes.io/*", Rule{Target: Annotation, Pattern: "pv.kubernetes.io/*"}, false},
+		{"!label:pod-template-hash", Rule{Target: Label, Pattern: "pod-template-hash", Keep: true}, false},
+		{"field:managedFields", Rule{Target: Field, Pattern: "managedFields"}, false},
+		{"annotation", Rule{}, true},
+		{"annotation:", Rule{}, true},
+		{"spec:replicas", Rule{}, true},
+		{"label:[", Rule{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := ParseRule(tt.rule)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.Target, got.Target)
+			assert.Equal(t, tt.want.Pattern, got.Pattern)
+			assert.Equal(t, tt.want.Keep, got.Keep)
+		})
+	}
+}
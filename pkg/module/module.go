@@ -0,0 +1,126 @@
+// Package module lays out the config generated by the converter as a reusable Terraform module, with the
+// resources, input variables, outputs and provider requirements in separate files.
+package module
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/sl1pm4t/k2tf/pkg/converter"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Names of the files of a module
+const (
+	MainFile      = "main.tf"
+	VariablesFile = "variables.tf"
+	OutputsFile   = "outputs.tf"
+	VersionsFile  = "versions.tf"
+)
+
+// Files lists the files of a module, in the order they're written
+var Files = []string{MainFile, VariablesFile, OutputsFile, VersionsFile}
+
+const manifestResourceType = "kubernetes_manifest"
+
+// Module holds the config of each file of a module
+type Module struct {
+	// Main holds the resources, and the local values they use
+	Main *tfconfig.Body
+	// Variables holds the input variables
+	Variables *tfconfig.Body
+	// Outputs holds an output for the name and ID of each resource
+	Outputs *tfconfig.Body
+	// Versions holds the required providers
+	Versions *tfconfig.Body
+}
+
+// New lays out the config generated for results as a module. Variable blocks are moved to the variables file,
+// and an output is generated for the name and ID of each resource. The Kubernetes provider is required with
+// the given version constraint, or without a constraint when it's empty.
+func New(results []*converter.Result, constraint string) *Module {
+	m := &Module{
+		Main:      tfconfig.NewBody(),
+		Variables: tfconfig.NewBody(),
+		Outputs:   tfconfig.NewBody(),
+		Versions:  tfconfig.NewBody(),
+	}
+
+	for _, r := range results {
+		if r.Skipped || r.Config == nil {
+			continue
+		}
+
+		for _, b := range r.Config.Blocks() {
+			if b.Type == "variable" {
+				m.Variables.AppendBlock(b)
+			} else {
+				m.Main.AppendBlock(b)
+			}
+		}
+
+		m.writeOutputs(r)
+	}
+
+	converter.WriteRequiredProviders(constraint, m.Versions)
+
+	return m
+}
+
+// HCL returns the formatted content of the named file
+func (m *Module) HCL(file string) []byte {
+	var body *tfconfig.Body
+	switch file {
+	case MainFile:
+		body = m.Main
+	case VariablesFile:
+		body = m.Variables
+	case OutputsFile:
+		body = m.Outputs
+	case VersionsFile:
+		body = m.Versions
+	default:
+		return nil
+	}
+
+	return hclwrite.Format(body.HCL())
+}
+
+// writeOutputs appends outputs for the name and ID of the resource generated for r, e.g.
+// deployment_backend_name and deployment_backend_id. kubernetes_manifest resources have no ID, only the name
+// is output.
+func (m *Module) writeOutputs(r *converter.Result) {
+	prefix := strings.TrimPrefix(r.ResourceType, "kubernetes_") + "_" + r.ResourceName
+	resource := hcl.Traversal{
+		hcl.TraverseRoot{Name: r.ResourceType},
+		hcl.TraverseAttr{Name: r.ResourceName},
+	}
+
+	if r.ResourceType == manifestResourceType {
+		name := append(resource,
+			hcl.TraverseAttr{Name: "object"},
+			hcl.TraverseAttr{Name: "metadata"},
+			hcl.TraverseAttr{Name: "name"},
+		)
+		m.writeOutput(prefix+"_name", "Name of the "+r.Address()+" object", name)
+		return
+	}
+
+	name := append(resource,
+		hcl.TraverseAttr{Name: "metadata"},
+		hcl.TraverseIndex{Key: cty.NumberIntVal(0)},
+		hcl.TraverseAttr{Name: "name"},
+	)
+	id := append(resource, hcl.TraverseAttr{Name: "id"})
+	m.writeOutput(prefix+"_name", "Name of the "+r.Address()+" object", name)
+	m.writeOutput(prefix+"_id", "ID of the "+r.Address()+" resource", id)
+}
+
+func (m *Module) writeOutput(name, description string, value hcl.Traversal) {
+	block := tfconfig.NewBlock("output", []string{name})
+	block.Body.SetAttributeValue("description", cty.StringVal(description))
+	block.Body.SetAttributeTraversal("value", value)
+	m.Outputs.AppendBlock(block)
+}
@@ -0,0 +1,60 @@
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/converter"
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = strings.ToLower(os.Getenv("UPDATE_GOLDEN")) == "true"
+
+func TestNew(t *testing.T) {
+	f, err := os.Open(filepath.Join("../../test-fixtures", "parameters", "shop.yaml"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	objs, err := k8sparser.ParseYAML(f)
+	require.NoError(t, err)
+
+	results, err := converter.New(converter.Options{
+		ParameterPaths:     converter.DefaultParameterPaths,
+		ParameterVariables: true,
+	}).Convert(objs)
+	require.NoError(t, err)
+
+	m := New(results, "~> 2.19")
+
+	for _, file := range Files {
+		t.Run(file, func(t *testing.T) {
+			// Read our golden file (or optionally write if env var is set)
+			goldenFile := filepath.Join("../../test-fixtures", "module", file+".golden")
+			if update {
+				os.WriteFile(goldenFile, m.HCL(file), 0644)
+			}
+			expected, err := os.ReadFile(goldenFile)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(expected), string(m.HCL(file)), "should be equal")
+		})
+	}
+}
+
+func TestNew_noConstraint(t *testing.T) {
+	m := New(nil, "")
+
+	assert.Equal(t, `terraform {
+  required_providers {
+    kubernetes = {
+      source = "hashicorp/kubernetes"
+    }
+  }
+}
+`, string(m.HCL(VersionsFile)))
+	assert.Empty(t, m.HCL(MainFile))
+}
@@ -0,0 +1,125 @@
+// Package report builds a machine-readable summary of a conversion, listing every object that was
+// converted or skipped, every field that was excluded from the generated config, and input that could
+// not be parsed.
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/sl1pm4t/k2tf/pkg/converter"
+	"github.com/sl1pm4t/k2tf/pkg/file_io"
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/sl1pm4t/k2tf/pkg/verify"
+)
+
+// Report is the summary of a conversion
+type Report struct {
+	Summary Summary `json:"summary"`
+	// Objects lists every object read from input, in input order
+	Objects []Object `json:"objects"`
+	// SkippedKinds lists the kinds of skipped objects, sorted
+	SkippedKinds []string `json:"skippedKinds"`
+	// ParseErrors lists input that could not be parsed
+	ParseErrors []file_io.ParseError `json:"parseErrors"`
+}
+
+// Summary holds the totals of a Report
+type Summary struct {
+	Objects       int `json:"objects"`
+	Converted     int `json:"converted"`
+	Skipped       int `json:"skipped"`
+	SkippedFields int `json:"skippedFields"`
+	ParseErrors   int `json:"parseErrors"`
+	// Differences found by verifying the conversion, if enabled
+	Differences int `json:"differences"`
+}
+
+// Object describes the conversion of a single Kubernetes API Object
+type Object struct {
+	// Source is the location the object was read from, if known
+	Source     string `json:"source,omitempty"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Address of the generated Terraform resource, empty when the object was skipped
+	Address string `json:"address,omitempty"`
+	// LastApplied is true when the last-applied-configuration annotation of the object was converted
+	LastApplied bool `json:"lastApplied,omitempty"`
+	// Skipped is true when the object kind is not supported by the Terraform provider
+	Skipped bool `json:"skipped"`
+	// SkippedFields lists the fields that were excluded from the generated config
+	SkippedFields []converter.SkippedField `json:"skippedFields"`
+	// Differences found by verifying the conversion, if enabled
+	Differences []verify.Difference `json:"differences,omitempty"`
+}
+
+// New returns an empty Report
+func New() *Report {
+	return &Report{
+		Objects:      []Object{},
+		SkippedKinds: []string{},
+		ParseErrors:  []file_io.ParseError{},
+	}
+}
+
+// AddResult adds the outcome of converting an object to the report.
+// source is the location the object was read from, and diffs the differences found by verify.Verify;
+// both may be empty.
+func (r *Report) AddResult(res *converter.Result, source string, diffs []verify.Difference) {
+	tm := k8sutils.TypeMeta(res.Object)
+	om := k8sutils.ObjectMeta(res.Object)
+
+	o := Object{
+		Source:        source,
+		APIVersion:    tm.APIVersion,
+		Kind:          tm.Kind,
+		Namespace:     om.Namespace,
+		Name:          om.Name,
+		Address:       res.Address(),
+		LastApplied:   res.LastApplied,
+		Skipped:       res.Skipped,
+		SkippedFields: res.SkippedFields,
+		Differences:   diffs,
+	}
+	if o.SkippedFields == nil {
+		o.SkippedFields = []converter.SkippedField{}
+	}
+
+	r.Summary.Objects++
+	if res.Skipped {
+		r.Summary.Skipped++
+		r.addSkippedKind(tm.Kind)
+	} else {
+		r.Summary.Converted++
+	}
+	r.Summary.SkippedFields += len(res.SkippedFields)
+	r.Summary.Differences += len(diffs)
+
+	r.Objects = append(r.Objects, o)
+}
+
+// AddParseErrors adds input that could not be parsed to the report
+func (r *Report) AddParseErrors(errs []file_io.ParseError) {
+	r.ParseErrors = append(r.ParseErrors, errs...)
+	r.Summary.ParseErrors += len(errs)
+}
+
+func (r *Report) addSkippedKind(kind string) {
+	i := sort.SearchStrings(r.SkippedKinds, kind)
+	if i < len(r.SkippedKinds) && r.SkippedKinds[i] == kind {
+		return
+	}
+	r.SkippedKinds = append(r.SkippedKinds, "")
+	copy(r.SkippedKinds[i+1:], r.SkippedKinds[i:])
+	r.SkippedKinds[i] = kind
+}
+
+// Write writes the report to w as indented JSON
+func (r *Report) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/converter"
+	"github.com/sl1pm4t/k2tf/pkg/file_io"
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"github.com/sl1pm4t/k2tf/pkg/verify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const input = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: cert-manager
+spec:
+  finalizers: ["foo"]
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: first
+  namespace: cert-manager
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: cert-manager
+data:
+  key: value
+---
+apiVersion: example.com/v1
+kind: Gadget
+metadata:
+  name: second
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: third
+`
+
+func TestReport(t *testing.T) {
+	objs, err := k8sparser.ParseYAML(strings.NewReader(input))
+	require.NoError(t, err)
+
+	results, err := converter.New(converter.Options{}).Convert(objs)
+	require.NoError(t, err)
+
+	r := New()
+	r.AddResult(results[0], "namespace.yaml", []verify.Difference{{Kind: verify.Dropped, Path: "spec.finalizers", Want: []interface{}{"foo"}}})
+	for _, res := range results[1:] {
+		r.AddResult(res, "", nil)
+	}
+	r.AddParseErrors([]file_io.ParseError{{Source: "invalid.yaml", Error: "yaml: line 4: did not find expected ',' or ']'"}})
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Write(&buf))
+
+	assert.JSONEq(t, `{
+  "summary": {"objects": 5, "converted": 2, "skipped": 3, "skippedFields": 1, "parseErrors": 1, "differences": 1},
+  "objects": [
+    {
+      "source": "namespace.yaml",
+      "apiVersion": "v1",
+      "kind": "Namespace",
+      "name": "cert-manager",
+      "address": "kubernetes_namespace.cert_manager",
+      "skipped": false,
+      "skippedFields": [{"field": "Namespace.Spec", "schema": "kubernetes_namespace.spec"}],
+      "differences": [{"kind": "dropped", "path": "spec.finalizers", "want": ["foo"]}]
+    },
+    {"apiVersion": "example.com/v1", "kind": "Widget", "namespace": "cert-manager", "name": "first", "skipped": true, "skippedFields": []},
+    {"apiVersion": "v1", "kind": "ConfigMap", "namespace": "cert-manager", "name": "settings", "address": "kubernetes_config_map.settings", "skipped": false, "skippedFields": []},
+    {"apiVersion": "example.com/v1", "kind": "Gadget", "name": "second", "skipped": true, "skippedFields": []},
+    {"apiVersion": "example.com/v1", "kind": "Widget", "name": "third", "skipped": true, "skippedFields": []}
+  ],
+  "skippedKinds": ["Gadget", "Widget"],
+  "parseErrors": [{"source": "invalid.yaml", "error": "yaml: line 4: did not find expected ',' or ']'"}]
+}`, buf.String())
+}
+
+func TestNew_empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, New().Write(&buf))
+
+	assert.JSONEq(t, `{
+  "summary": {"objects": 0, "converted": 0, "skipped": 0, "skippedFields": 0, "parseErrors": 0, "differences": 0},
+  "objects": [],
+  "skippedKinds": [],
+  "parseErrors": []
+}`, buf.String())
+}
@@ -0,0 +1,608 @@
+// Package tf2k converts Terraform HCL configuration for the Terraform Kubernetes Provider
+// back to Kubernetes API Objects. It's the inverse of the converter package.
+package tf2k
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const manifestResourceType = "kubernetes_manifest"
+
+var (
+	quantityType    = reflect.TypeOf(resource.Quantity{})
+	intOrStringType = reflect.TypeOf(intstr.IntOrString{})
+	timeType        = reflect.TypeOf(metav1.Time{})
+)
+
+// Result holds the outcome of decoding a single Terraform resource
+type Result struct {
+	// Object is the decoded Kubernetes API Object
+	Object runtime.Object
+	// ResourceType is the Terraform resource type, e.g. kubernetes_deployment
+	ResourceType string
+	// ResourceName is the Terraform resource name
+	ResourceName string
+	// Warnings raised while decoding the resource, e.g. attributes that have no matching Kubernetes field
+	Warnings []string
+}
+
+// Address returns the address of the decoded Terraform resource
+// e.g. kubernetes_deployment.backend_api
+func (r *Result) Address() string {
+	return r.ResourceType + "." + r.ResourceName
+}
+
+// Decode decodes the kubernetes_* resources in the given Terraform configuration
+func Decode(src []byte, filename string) ([]*Result, error) {
+	p := hclparse.NewParser()
+	f, diags := p.ParseHCL(src, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return decodeFiles([]*hcl.File{f})
+}
+
+// DecodeFiles decodes the kubernetes_* resources in the given Terraform configuration files.
+// References between resources in the files (e.g. kubernetes_namespace.x.metadata[0].name) are resolved
+// to the referenced value, as are references to variables with a default value.
+func DecodeFiles(filenames []string) ([]*Result, error) {
+	p := hclparse.NewParser()
+
+	var files []*hcl.File
+	for _, fn := range filenames {
+		f, diags := p.ParseHCLFile(fn)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		files = append(files, f)
+	}
+
+	return decodeFiles(files)
+}
+
+func decodeFiles(files []*hcl.File) ([]*Result, error) {
+	var result error
+
+	var blocks []*hclsyntax.Block
+	for _, f := range files {
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			return nil, fmt.Errorf("unsupported configuration syntax")
+		}
+		blocks = append(blocks, body.Blocks...)
+	}
+
+	ctx := evalContext(blocks)
+
+	var results []*Result
+	for _, b := range blocks {
+		if b.Type != "resource" || len(b.Labels) != 2 || !strings.HasPrefix(b.Labels[0], "kubernetes_") {
+			continue
+		}
+
+		r, err := decodeResource(b, ctx)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("error decoding %s.%s: %w", b.Labels[0], b.Labels[1], err))
+			continue
+		}
+		if r != nil {
+			results = append(results, r)
+		}
+	}
+
+	return results, result
+}
+
+// DecodeResource decodes a single kubernetes_* resource block
+func DecodeResource(block *hclsyntax.Block) (*Result, error) {
+	return decodeResource(block, evalContext([]*hclsyntax.Block{block}))
+}
+
+// DecodeResourceAs decodes the resource with the given address in src to a Kubernetes API Object of kind gvk,
+// instead of the preferred API version for the resource type. e.g. to decode a kubernetes_deployment as an
+// extensions/v1beta1 Deployment.
+func DecodeResourceAs(src []byte, filename, address string, gvk k8sschema.GroupVersionKind) (*Result, error) {
+	p := hclparse.NewParser()
+	f, diags := p.ParseHCL(src, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unsupported configuration syntax")
+	}
+
+	for _, b := range body.Blocks {
+		if b.Type == "resource" && len(b.Labels) == 2 && b.Labels[0]+"."+b.Labels[1] == address {
+			return decodeResourceAs(b, evalContext(body.Blocks), gvk)
+		}
+	}
+
+	return nil, fmt.Errorf("resource %s not found", address)
+}
+
+func decodeResource(block *hclsyntax.Block, ctx *hcl.EvalContext) (*Result, error) {
+	gvk, ok := tfkschema.ToKubernetesGroupVersionKind(block.Labels[0])
+	if !ok && block.Labels[0] != manifestResourceType {
+		log.Warn().
+			Str("type", block.Labels[0]).
+			Str("name", block.Labels[1]).
+			Msgf("skipping resource, no Kubernetes kind found for resource type [%s]", block.Labels[0])
+		return nil, nil
+	}
+
+	return decodeResourceAs(block, ctx, gvk)
+}
+
+func decodeResourceAs(block *hclsyntax.Block, ctx *hcl.EvalContext, gvk k8sschema.GroupVersionKind) (*Result, error) {
+	d := &decoder{
+		ctx: ctx,
+		result: &Result{
+			ResourceType: block.Labels[0],
+			ResourceName: block.Labels[1],
+		},
+	}
+
+	if d.result.ResourceType == manifestResourceType {
+		return d.result, d.decodeManifest(block.Body)
+	}
+
+	obj, err := scheme.Scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	fields := d.decodeBody(block.Body, reflect.TypeOf(obj), tfkschema.ToTerraformResourceType(obj))
+	fields["apiVersion"], fields["kind"] = gvk.ToAPIVersionAndKind()
+
+	content, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, obj); err != nil {
+		return nil, err
+	}
+
+	d.result.Object = obj
+	return d.result, nil
+}
+
+// decodeManifest decodes the manifest attribute of a kubernetes_manifest resource to an unstructured object
+func (d *decoder) decodeManifest(body *hclsyntax.Body) error {
+	attr, ok := body.Attributes["manifest"]
+	if !ok {
+		return fmt.Errorf("missing manifest attribute")
+	}
+
+	val, diags := attr.Expr.Value(d.ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	content, err := ctyjson.SimpleJSONValue{Value: val}.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(content); err != nil {
+		return err
+	}
+
+	d.result.Object = obj
+	return nil
+}
+
+// decoder builds the JSON representation of a Kubernetes API Object from a Terraform resource block,
+// using the Go type of the object to map Terraform attribute and block names back to Kubernetes fields.
+type decoder struct {
+	ctx    *hcl.EvalContext
+	result *Result
+}
+
+// decodeBody returns the JSON fields of the Kubernetes struct type ty, decoded from body.
+// path is the Terraform schema path of the body, e.g. kubernetes_deployment.spec
+func (d *decoder) decodeBody(body *hclsyntax.Body, ty reflect.Type, path string) map[string]interface{} {
+	fields := map[string]interface{}{}
+
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		attr := body.Attributes[name]
+
+		field, ok := tfkschema.ToKubernetesField(ty, name, path)
+		if !ok {
+			d.warnf("skipping attribute [%s.%s], no matching Kubernetes field", path, name)
+			continue
+		}
+
+		var v interface{}
+		if obj, ok := attr.Expr.(*hclsyntax.ObjectConsExpr); ok && derefType(field.Type).Kind() == reflect.Map {
+			// decode map entries individually, so entries that can't be evaluated (e.g. references to
+			// variables without a default value) don't cause the whole map to be skipped
+			v = d.decodeMapExpr(obj, derefType(field.Type), path+"."+name)
+
+		} else {
+			val, diags := attr.Expr.Value(d.ctx)
+			if diags.HasErrors() {
+				d.warnf("skipping attribute [%s.%s], could not evaluate expression: %s", path, name, diags.Error())
+				continue
+			}
+
+			var err error
+			v, err = toJSONValue(val, field.Type)
+			if err != nil {
+				d.warnf("skipping attribute [%s.%s]: %s", path, name, err)
+				continue
+			}
+		}
+
+		if v != nil {
+			fields[tfkschema.ToKubernetesFieldName(&field)] = v
+		}
+	}
+
+	for _, b := range body.Blocks {
+		blockPath := path + "." + b.Type
+
+		field, ok := tfkschema.ToKubernetesField(ty, b.Type, path)
+		if !ok {
+			d.warnf("skipping block [%s], no matching Kubernetes field", blockPath)
+			continue
+		}
+		name := tfkschema.ToKubernetesFieldName(&field)
+
+		ft := derefType(field.Type)
+		switch ft.Kind() {
+		case reflect.Struct:
+			if _, exists := fields[name]; exists {
+				d.warnf("skipping repeated block [%s]", blockPath)
+				continue
+			}
+			fields[name] = d.decodeBody(b.Body, ft, blockPath)
+
+		case reflect.Slice:
+			elems, _ := fields[name].([]interface{})
+			if derefType(ft.Elem()).Kind() == reflect.Struct {
+				elems = append(elems, d.decodeBody(b.Body, derefType(ft.Elem()), blockPath))
+			} else {
+				d.warnf("skipping block [%s], Kubernetes field is not a list of objects", blockPath)
+				continue
+			}
+			fields[name] = elems
+
+		case reflect.Map:
+			// maps that are not typed as maps in the Terraform schema are written as blocks,
+			// e.g. resources { limits { cpu = "1" } }
+			fields[name] = d.decodeMapBody(b.Body, ft, blockPath)
+
+		default:
+			d.warnf("skipping block [%s], Kubernetes field is not an object", blockPath)
+		}
+	}
+
+	return fields
+}
+
+// decodeMapBody returns the entries of a Go map of type ty, decoded from the attributes of body
+func (d *decoder) decodeMapBody(body *hclsyntax.Body, ty reflect.Type, path string) map[string]interface{} {
+	m := map[string]interface{}{}
+	for name, attr := range body.Attributes {
+		val, diags := attr.Expr.Value(d.ctx)
+		if diags.HasErrors() {
+			d.warnf("skipping attribute [%s.%s], could not evaluate expression: %s", path, name, diags.Error())
+			continue
+		}
+
+		v, err := toJSONValue(val, ty.Elem())
+		if err != nil {
+			d.warnf("skipping attribute [%s.%s]: %s", path, name, err)
+			continue
+		}
+		m[name] = v
+	}
+
+	return m
+}
+
+// decodeMapExpr returns the entries of a Go map of type ty, decoded from an object constructor expression
+func (d *decoder) decodeMapExpr(expr *hclsyntax.ObjectConsExpr, ty reflect.Type, path string) map[string]interface{} {
+	m := map[string]interface{}{}
+	for _, item := range expr.Items {
+		key, diags := item.KeyExpr.Value(d.ctx)
+		if diags.HasErrors() || key.Type() != cty.String || !key.IsKnown() {
+			d.warnf("skipping map key in [%s], could not evaluate expression: %s", path, diags.Error())
+			continue
+		}
+
+		val, diags := item.ValueExpr.Value(d.ctx)
+		if diags.HasErrors() {
+			d.warnf("skipping map entry [%s.%s], could not evaluate expression: %s", path, key.AsString(), diags.Error())
+			continue
+		}
+
+		v, err := toJSONValue(val, ty.Elem())
+		if err != nil {
+			d.warnf("skipping map entry [%s.%s]: %s", path, key.AsString(), err)
+			continue
+		}
+		m[key.AsString()] = v
+	}
+
+	return m
+}
+
+func (d *decoder) warnf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	log.Warn().
+		Str("type", d.result.ResourceType).
+		Str("name", d.result.ResourceName).
+		Msg(msg)
+	d.result.Warnings = append(d.result.Warnings, msg)
+}
+
+// toJSONValue converts val to the JSON representation of the Go type ty
+func toJSONValue(val cty.Value, ty reflect.Type) (interface{}, error) {
+	ty = derefType(ty)
+
+	if val.IsNull() {
+		return nil, nil
+	}
+	if !val.IsWhollyKnown() {
+		return nil, fmt.Errorf("value is not known")
+	}
+
+	switch ty {
+	case quantityType, timeType:
+		return toString(val)
+
+	case intOrStringType:
+		s, err := toString(val)
+		if err != nil {
+			return nil, err
+		}
+		if i, err := strconv.Atoi(s); err == nil {
+			return i, nil
+		}
+		return s, nil
+	}
+
+	switch ty.Kind() {
+	case reflect.String:
+		return toString(val)
+
+	case reflect.Bool:
+		v, err := convert.Convert(val, cty.Bool)
+		if err != nil {
+			return nil, err
+		}
+		return v.True(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if val.Type() == cty.String {
+			// e.g. volume modes are written as octal strings: "0644"
+			return strconv.ParseInt(val.AsString(), 0, 64)
+		}
+		v, err := convert.Convert(val, cty.Number)
+		if err != nil {
+			return nil, err
+		}
+		i, _ := v.AsBigFloat().Int64()
+		return i, nil
+
+	case reflect.Float32, reflect.Float64:
+		v, err := convert.Convert(val, cty.Number)
+		if err != nil {
+			return nil, err
+		}
+		f, _ := v.AsBigFloat().Float64()
+		return f, nil
+
+	case reflect.Slice:
+		if ty.Elem().Kind() == reflect.Uint8 {
+			// []byte fields (e.g. Secret data) are written as plain strings, but base64 encoded in JSON
+			if val.Type().IsListType() || val.Type().IsTupleType() {
+				// or as a list of bytes
+				var b []byte
+				for it := val.ElementIterator(); it.Next(); {
+					_, ev := it.Element()
+					i, err := toJSONValue(ev, ty.Elem())
+					if err != nil {
+						return nil, err
+					}
+					b = append(b, byte(i.(int64)))
+				}
+				return base64.StdEncoding.EncodeToString(b), nil
+			}
+
+			s, err := toString(val)
+			if err != nil {
+				return nil, err
+			}
+			return base64.StdEncoding.EncodeToString([]byte(s)), nil
+		}
+
+		if !val.CanIterateElements() || val.Type().IsMapType() || val.Type().IsObjectType() {
+			return nil, fmt.Errorf("expected a list, got %s", val.Type().FriendlyName())
+		}
+		elems := []interface{}{}
+		for it := val.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			v, err := toJSONValue(ev, ty.Elem())
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, v)
+		}
+		return elems, nil
+
+	case reflect.Map:
+		if !val.Type().IsMapType() && !val.Type().IsObjectType() {
+			return nil, fmt.Errorf("expected a map, got %s", val.Type().FriendlyName())
+		}
+		m := map[string]interface{}{}
+		for it := val.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			v, err := toJSONValue(ev, ty.Elem())
+			if err != nil {
+				return nil, err
+			}
+			m[k.AsString()] = v
+		}
+		return m, nil
+
+	case reflect.Struct:
+		// the HCL1 formatter writes empty blocks as empty objects, e.g. empty_dir = {}
+		if (val.Type().IsObjectType() || val.Type().IsMapType()) && val.LengthInt() == 0 {
+			return map[string]interface{}{}, nil
+		}
+
+	case reflect.Interface:
+		content, err := ctyjson.SimpleJSONValue{Value: val}.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		var v interface{}
+		err = json.Unmarshal(content, &v)
+		return v, err
+	}
+
+	return nil, fmt.Errorf("unsupported Kubernetes field type %s", ty)
+}
+
+func toString(val cty.Value) (string, error) {
+	if val.Type() == cty.Number {
+		// avoid the exponent notation used by convert for large numbers
+		return val.AsBigFloat().Text('f', -1), nil
+	}
+
+	v, err := convert.Convert(val, cty.String)
+	if err != nil {
+		return "", err
+	}
+	return v.AsString(), nil
+}
+
+func derefType(ty reflect.Type) reflect.Type {
+	for ty.Kind() == reflect.Ptr {
+		ty = ty.Elem()
+	}
+	return ty
+}
+
+// evalContext returns an HCL evaluation context that resolves references to the metadata of resources
+// in blocks, to variables with a default value, and to local values set to a literal value.
+// The metadata of a resource may itself reference variables, local values and other resources,
+// e.g. namespace = kubernetes_namespace.x.metadata[0].name.
+func evalContext(blocks []*hclsyntax.Block) *hcl.EvalContext {
+	vars := map[string]cty.Value{}
+	locals := map[string]cty.Value{}
+
+	for _, b := range blocks {
+		switch {
+		case b.Type == "variable" && len(b.Labels) == 1:
+			if attr, ok := b.Body.Attributes["default"]; ok {
+				if val, diags := attr.Expr.Value(nil); !diags.HasErrors() {
+					vars[b.Labels[0]] = val
+				}
+			}
+
+		case b.Type == "locals":
+			for name, attr := range b.Body.Attributes {
+				if val, diags := attr.Expr.Value(nil); !diags.HasErrors() {
+					locals[name] = val
+				}
+			}
+		}
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{},
+	}
+	if len(vars) > 0 {
+		ctx.Variables["var"] = cty.ObjectVal(vars)
+	}
+	if len(locals) > 0 {
+		ctx.Variables["local"] = cty.ObjectVal(locals)
+	}
+
+	// the second pass resolves references to the resources found by the first one
+	for pass := 0; pass < 2; pass++ {
+		resources := resourceValues(blocks, ctx)
+		for ty, byName := range resources {
+			ctx.Variables[ty] = cty.ObjectVal(byName)
+		}
+	}
+
+	return ctx
+}
+
+// resourceValues returns the values of the metadata and manifest attributes of the resources in blocks,
+// by resource type and name
+func resourceValues(blocks []*hclsyntax.Block, ctx *hcl.EvalContext) map[string]map[string]cty.Value {
+	resources := map[string]map[string]cty.Value{}
+
+	for _, b := range blocks {
+		if b.Type != "resource" || len(b.Labels) != 2 {
+			continue
+		}
+		attrs := map[string]cty.Value{}
+
+		for _, mb := range b.Body.Blocks {
+			if mb.Type != "metadata" {
+				continue
+			}
+			meta := map[string]cty.Value{}
+			for name, attr := range mb.Body.Attributes {
+				if val, diags := attr.Expr.Value(ctx); !diags.HasErrors() {
+					meta[name] = val
+				}
+			}
+			attrs["metadata"] = cty.TupleVal([]cty.Value{cty.ObjectVal(meta)})
+		}
+
+		if attr, ok := b.Body.Attributes["manifest"]; ok {
+			if val, diags := attr.Expr.Value(ctx); !diags.HasErrors() {
+				attrs["manifest"] = val
+			}
+		}
+
+		if resources[b.Labels[0]] == nil {
+			resources[b.Labels[0]] = map[string]cty.Value{}
+		}
+		resources[b.Labels[0]][b.Labels[1]] = cty.ObjectVal(attrs)
+	}
+
+	return resources
+}
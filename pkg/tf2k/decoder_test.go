@@ -0,0 +1,136 @@
+package tf2k
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var update = strings.ToLower(os.Getenv("UPDATE_GOLDEN")) == "true"
+
+func TestDecodeFiles(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantWarnings int
+	}{
+		{"deployment", "deployment.tf.golden", 0},
+		{"volumes", "podNodeExporter.tf.golden", 0},
+		{"references", "references/app.tf.golden", 0},
+		{"secret_variables", "secrets/secret.tf.golden", 3},
+		{"parameters", "parameters/shop.tf.golden", 0},
+		{"byte_list", "certificateSigningRequest.tf.golden", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := DecodeFiles([]string{filepath.Join("../../test-fixtures", tt.input)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var objs []runtime.Object
+			var warnings []string
+			for _, r := range results {
+				objs = append(objs, r.Object)
+				warnings = append(warnings, r.Warnings...)
+			}
+			assert.Len(t, warnings, tt.wantWarnings)
+
+			var buf bytes.Buffer
+			if err := WriteYAML(&buf, objs); err != nil {
+				t.Fatal(err)
+			}
+
+			// Read our golden file (or optionally write if env var is set)
+			goldenFile := filepath.Join("../../test-fixtures", "tf2k", tt.name+".yaml.golden")
+			if update {
+				os.WriteFile(goldenFile, buf.Bytes(), 0644)
+			}
+			expected, err := os.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, string(expected), buf.String(), "should be equal")
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	src := `
+variable "replicas" {
+  default = 3
+}
+
+resource "kubernetes_deployment_v1" "web" {
+  metadata {
+    name      = "web"
+    namespace = kubernetes_namespace.apps.metadata[0].name
+  }
+  spec {
+    replicas = var.replicas
+    unknown  = true
+  }
+}
+
+resource "kubernetes_namespace" "apps" {
+  metadata {
+    name = "apps"
+  }
+}
+
+resource "kubernetes_manifest" "crontab_my_cron" {
+  manifest = {
+    apiVersion = "stable.example.com/v1"
+    kind       = "CronTab"
+    metadata = {
+      name = "my-cron"
+    }
+    spec = {
+      cronSpec = "* * * * */5"
+    }
+  }
+}
+
+resource "null_resource" "other" {}
+`
+	results, err := Decode([]byte(src), "main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, results, 3) {
+		return
+	}
+
+	assert.Equal(t, "kubernetes_deployment_v1.web", results[0].Address())
+	assert.Equal(t, []string{"skipping attribute [kubernetes_deployment.spec.unknown], no matching Kubernetes field"}, results[0].Warnings)
+
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, []runtime.Object{results[0].Object, results[2].Object}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: apps
+spec:
+  replicas: 3
+  strategy: {}
+  template:
+    metadata: {}
+    spec: {}
+---
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: my-cron
+spec:
+  cronSpec: '* * * * */5'
+`, buf.String())
+}
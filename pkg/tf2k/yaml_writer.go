@@ -0,0 +1,62 @@
+package tf2k
+
+import (
+	"encoding/json"
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// WriteYAML writes objs to w as a multi-document YAML stream.
+// Fields that are never set by Terraform (e.g. status, creationTimestamp) are omitted.
+func WriteYAML(w io.Writer, objs []runtime.Object) error {
+	for i, obj := range objs {
+		content, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal(content, &fields); err != nil {
+			return err
+		}
+		delete(fields, "status")
+		removeUnset(fields)
+
+		doc, err := yaml.Marshal(fields)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(doc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeUnset recursively removes null values, and empty status fields from v, e.g. the creationTimestamp of
+// metadata that the typed API structs always marshal.
+func removeUnset(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if status, ok := e.(map[string]interface{}); e == nil || (ok && k == "status" && len(status) == 0) {
+				delete(v, k)
+				continue
+			}
+			removeUnset(e)
+		}
+	case []interface{}:
+		for _, e := range v {
+			removeUnset(e)
+		}
+	}
+}
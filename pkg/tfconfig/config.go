@@ -0,0 +1,158 @@
+// Package tfconfig is a format-neutral representation of Terraform configuration, that can be written
+// in HCL native syntax (.tf) or JSON syntax (.tf.json).
+package tfconfig
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Body holds the attributes and nested blocks of a Block, or of a configuration file, in the order
+// they were added.
+type Body struct {
+	items []interface{}
+}
+
+// NewBody returns an empty Body
+func NewBody() *Body {
+	return &Body{}
+}
+
+// Block is a Terraform block, e.g. a resource block or a nested block such as container
+type Block struct {
+	Type   string
+	Labels []string
+	Body   *Body
+
+	// Comment is written above the block in HCL syntax, or as a "//" property in JSON syntax
+	Comment string
+}
+
+// NewBlock returns a new Block with an empty Body
+func NewBlock(typeName string, labels []string) *Block {
+	return &Block{
+		Type:   typeName,
+		Labels: labels,
+		Body:   NewBody(),
+	}
+}
+
+// Attribute is a Terraform attribute, e.g. replicas = 3
+type Attribute struct {
+	Name string
+	Expr Expression
+}
+
+// Expression is the value of an Attribute. Exactly one of Value, Traversal or Object is set.
+type Expression struct {
+	// Value is a literal value
+	Value cty.Value
+	// Traversal is a reference, e.g. var.password
+	Traversal hcl.Traversal
+	// Object is an object whose attributes aren't all literal values
+	Object map[string]Expression
+
+	// Static marks a Traversal that Terraform doesn't evaluate as an expression, e.g. the to argument of an
+	// import block, or a variable type. It's written without interpolation in JSON syntax.
+	Static bool
+}
+
+// ValueExpr returns an Expression for a literal value
+func ValueExpr(v cty.Value) Expression {
+	return Expression{Value: v}
+}
+
+// TraversalExpr returns an Expression for a reference
+func TraversalExpr(t hcl.Traversal) Expression {
+	return Expression{Traversal: t}
+}
+
+// AppendBlock appends a nested block to b, and returns it
+func (b *Body) AppendBlock(block *Block) *Block {
+	b.items = append(b.items, block)
+	return block
+}
+
+// SetAttribute sets the expression of the named attribute. An existing attribute keeps its position,
+// new attributes are appended.
+func (b *Body) SetAttribute(name string, expr Expression) *Attribute {
+	if attr := b.GetAttribute(name); attr != nil {
+		attr.Expr = expr
+		return attr
+	}
+
+	attr := &Attribute{Name: name, Expr: expr}
+	b.items = append(b.items, attr)
+	return attr
+}
+
+// SetAttributeValue sets the named attribute to a literal value
+func (b *Body) SetAttributeValue(name string, v cty.Value) *Attribute {
+	return b.SetAttribute(name, ValueExpr(v))
+}
+
+// SetAttributeTraversal sets the named attribute to a reference
+func (b *Body) SetAttributeTraversal(name string, t hcl.Traversal) *Attribute {
+	return b.SetAttribute(name, TraversalExpr(t))
+}
+
+// PrependAttribute sets the expression of the named attribute, moving it before all other attributes and
+// blocks, e.g. for meta-arguments such as for_each.
+func (b *Body) PrependAttribute(name string, expr Expression) *Attribute {
+	for i, item := range b.items {
+		if attr, ok := item.(*Attribute); ok && attr.Name == name {
+			b.items = append(b.items[:i], b.items[i+1:]...)
+			break
+		}
+	}
+
+	attr := &Attribute{Name: name, Expr: expr}
+	b.items = append([]interface{}{attr}, b.items...)
+	return attr
+}
+
+// GetAttribute returns the named attribute, or nil if it's not set
+func (b *Body) GetAttribute(name string) *Attribute {
+	for _, item := range b.items {
+		if attr, ok := item.(*Attribute); ok && attr.Name == name {
+			return attr
+		}
+	}
+	return nil
+}
+
+// Attributes returns the attributes of b, in order
+func (b *Body) Attributes() []*Attribute {
+	var attrs []*Attribute
+	for _, item := range b.items {
+		if attr, ok := item.(*Attribute); ok {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+// Blocks returns the nested blocks of b, in order
+func (b *Body) Blocks() []*Block {
+	var blocks []*Block
+	for _, item := range b.items {
+		if block, ok := item.(*Block); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// StringValue returns the value of the named attribute, if it's a literal string
+func (b *Body) StringValue(name string) (string, bool) {
+	attr := b.GetAttribute(name)
+	if attr == nil || attr.Expr.Value == cty.NilVal {
+		return "", false
+	}
+
+	v := attr.Expr.Value
+	if v.Type() != cty.String || v.IsNull() || !v.IsKnown() {
+		return "", false
+	}
+	return v.AsString(), true
+}
@@ -0,0 +1,123 @@
+package tfconfig
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func testBody() *Body {
+	body := NewBody()
+
+	resource := body.AppendBlock(NewBlock("resource", []string{"kubernetes_config_map", "settings"}))
+	resource.Comment = "Source: settings.yaml"
+	metadata := resource.Body.AppendBlock(NewBlock("metadata", nil))
+	metadata.Body.SetAttributeValue("name", cty.StringVal("settings"))
+	metadata.Body.SetAttributeValue("namespace", cty.StringVal("default"))
+	metadata.Body.SetAttributeTraversal("namespace", hcl.Traversal{
+		hcl.TraverseRoot{Name: "var"},
+		hcl.TraverseAttr{Name: "namespace"},
+	})
+	resource.Body.SetAttributeValue("data", cty.MapVal(map[string]cty.Value{
+		"greeting": cty.StringVal("hello ${name}"),
+	}))
+	resource.Body.SetAttribute("binary_data", Expression{Object: map[string]Expression{
+		"key.bin": TraversalExpr(hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: "key"}}),
+	}})
+
+	imp := body.AppendBlock(NewBlock("import", nil))
+	imp.Body.SetAttribute("to", Expression{
+		Traversal: hcl.Traversal{hcl.TraverseRoot{Name: "kubernetes_config_map"}, hcl.TraverseAttr{Name: "settings"}},
+		Static:    true,
+	})
+	imp.Body.SetAttributeValue("id", cty.StringVal("default/settings"))
+
+	return body
+}
+
+func TestBody_HCL(t *testing.T) {
+	expected := `# Source: settings.yaml
+resource "kubernetes_config_map" "settings" {
+  metadata {
+    name      = "settings"
+    namespace = var.namespace
+  }
+  data = {
+    greeting = "hello $${name}"
+  }
+  binary_data = {
+    "key.bin" = var.key
+  }
+}
+
+import {
+  to = kubernetes_config_map.settings
+  id = "default/settings"
+}
+`
+	assert.Equal(t, expected, string(hclwrite.Format(testBody().HCL())))
+}
+
+func TestBody_MarshalJSON(t *testing.T) {
+	expected := `{
+  "import": [
+    {
+      "id": "default/settings",
+      "to": "kubernetes_config_map.settings"
+    }
+  ],
+  "resource": {
+    "kubernetes_config_map": {
+      "settings": {
+        "//": "Source: settings.yaml",
+        "binary_data": {
+          "key.bin": "${var.key}"
+        },
+        "data": {
+          "greeting": "hello $${name}"
+        },
+        "metadata": [
+          {
+            "name": "settings",
+            "namespace": "${var.namespace}"
+          }
+        ]
+      }
+    }
+  }
+}`
+
+	content, err := json.MarshalIndent(testBody(), "", "  ")
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
+
+func TestBody_MarshalJSON_noHTMLEscape(t *testing.T) {
+	body := NewBody()
+	body.SetAttributeValue("version", cty.StringVal("~> 2.19"))
+
+	content, err := body.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":"~> 2.19"}`, string(content))
+}
+
+func TestBody_StringValue(t *testing.T) {
+	body := NewBody()
+	body.SetAttributeValue("name", cty.StringVal("web"))
+	body.SetAttributeValue("replicas", cty.NumberIntVal(3))
+	body.SetAttributeTraversal("namespace", hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: "ns"}})
+
+	v, ok := body.StringValue("name")
+	assert.True(t, ok)
+	assert.Equal(t, "web", v)
+
+	for _, name := range []string{"replicas", "namespace", "missing"} {
+		_, ok := body.StringValue(name)
+		assert.False(t, ok, name)
+	}
+}
@@ -0,0 +1,83 @@
+package tfconfig
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// HCL returns the unformatted HCL native syntax of b
+func (b *Body) HCL() []byte {
+	f := hclwrite.NewEmptyFile()
+	b.WriteHCL(f.Body())
+	return f.Bytes()
+}
+
+// WriteHCL appends the attributes and blocks of b to dst. Top level blocks are separated by an empty line.
+func (b *Body) WriteHCL(dst *hclwrite.Body) {
+	b.writeHCL(dst, true)
+}
+
+func (b *Body) writeHCL(dst *hclwrite.Body, topLevel bool) {
+	for i, item := range b.items {
+		switch item := item.(type) {
+		case *Attribute:
+			dst.SetAttributeRaw(item.Name, item.Expr.hclTokens())
+
+		case *Block:
+			if topLevel && i > 0 {
+				dst.AppendNewline()
+			}
+			if item.Comment != "" {
+				dst.AppendUnstructuredTokens(hclwrite.Tokens{
+					{Type: hclsyntax.TokenComment, Bytes: []byte("# " + strings.ReplaceAll(item.Comment, "\n", "\n# ") + "\n")},
+				})
+			}
+
+			block := hclwrite.NewBlock(item.Type, item.Labels)
+			item.Body.writeHCL(block.Body(), false)
+			dst.AppendBlock(block)
+		}
+	}
+}
+
+// Equal returns true when e and other are written the same in HCL syntax
+func (e Expression) Equal(other Expression) bool {
+	return string(e.hclTokens().Bytes()) == string(other.hclTokens().Bytes())
+}
+
+func (e Expression) hclTokens() hclwrite.Tokens {
+	switch {
+	case e.Traversal != nil:
+		return hclwrite.TokensForTraversal(e.Traversal)
+
+	case e.Object != nil:
+		keys := make([]string, 0, len(e.Object))
+		for k := range e.Object {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		attrs := make([]hclwrite.ObjectAttrTokens, 0, len(keys))
+		for _, k := range keys {
+			attrs = append(attrs, hclwrite.ObjectAttrTokens{
+				Name:  objectKeyTokens(k),
+				Value: e.Object[k].hclTokens(),
+			})
+		}
+		return hclwrite.TokensForObject(attrs)
+
+	default:
+		return hclwrite.TokensForValue(e.Value)
+	}
+}
+
+func objectKeyTokens(key string) hclwrite.Tokens {
+	if hclsyntax.ValidIdentifier(key) {
+		return hclwrite.TokensForIdentifier(key)
+	}
+	return hclwrite.TokensForValue(cty.StringVal(key))
+}
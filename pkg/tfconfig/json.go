@@ -0,0 +1,148 @@
+package tfconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// jsonCommentKey is the property name Terraform JSON syntax reserves for comments
+const jsonCommentKey = "//"
+
+var templateEscaper = strings.NewReplacer("${", "$${", "%{", "%%{")
+
+// MarshalJSON returns the Terraform JSON syntax of b.
+// Nested blocks are written as arrays of objects, blocks with labels as objects nested by label,
+// e.g. {"resource": {"kubernetes_namespace": {"example": {"metadata": [{"name": "example"}]}}}}
+// Characters such as < and > are not escaped, so that e.g. version constraints stay readable.
+func (b *Body) MarshalJSON() ([]byte, error) {
+	v, err := b.jsonValue(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (b *Body) jsonValue(comment *string) (map[string]interface{}, error) {
+	out := map[string]interface{}{}
+	if comment != nil && *comment != "" {
+		out[jsonCommentKey] = *comment
+	}
+
+	for _, item := range b.items {
+		switch item := item.(type) {
+		case *Attribute:
+			v, err := item.Expr.jsonValue()
+			if err != nil {
+				return nil, err
+			}
+			out[item.Name] = v
+
+		case *Block:
+			body, err := item.Body.jsonValue(&item.Comment)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(item.Labels) == 0 {
+				list, _ := out[item.Type].([]interface{})
+				out[item.Type] = append(list, body)
+				continue
+			}
+
+			// nest the body in an object per label
+			parent, _ := out[item.Type].(map[string]interface{})
+			if parent == nil {
+				parent = map[string]interface{}{}
+				out[item.Type] = parent
+			}
+			for _, l := range item.Labels[:len(item.Labels)-1] {
+				next, _ := parent[l].(map[string]interface{})
+				if next == nil {
+					next = map[string]interface{}{}
+					parent[l] = next
+				}
+				parent = next
+			}
+			parent[item.Labels[len(item.Labels)-1]] = body
+		}
+	}
+
+	return out, nil
+}
+
+func (e Expression) jsonValue() (interface{}, error) {
+	switch {
+	case e.Traversal != nil:
+		ref := strings.TrimSpace(string(hclwrite.TokensForTraversal(e.Traversal).Bytes()))
+		if e.Static {
+			return ref, nil
+		}
+		return "${" + ref + "}", nil
+
+	case e.Object != nil:
+		out := map[string]interface{}{}
+		for k, v := range e.Object {
+			jv, err := v.jsonValue()
+			if err != nil {
+				return nil, err
+			}
+			out[k] = jv
+		}
+		return out, nil
+
+	default:
+		return jsonLiteral(e.Value)
+	}
+}
+
+// jsonLiteral converts a literal value to JSON. Terraform evaluates JSON strings as templates, so template
+// sequences in strings are escaped.
+func jsonLiteral(v cty.Value) (interface{}, error) {
+	ty := v.Type()
+	switch {
+	case v.IsNull():
+		return nil, nil
+	case !v.IsWhollyKnown():
+		return nil, fmt.Errorf("unknown value")
+	case ty == cty.String:
+		return templateEscaper.Replace(v.AsString()), nil
+	case ty == cty.Number:
+		return json.Number(v.AsBigFloat().Text('f', -1)), nil
+	case ty == cty.Bool:
+		return v.True(), nil
+	case ty.IsObjectType() || ty.IsMapType():
+		out := map[string]interface{}{}
+		for k, e := range v.AsValueMap() {
+			jv, err := jsonLiteral(e)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = jv
+		}
+		return out, nil
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		out := []interface{}{}
+		for _, e := range v.AsValueSlice() {
+			jv, err := jsonLiteral(e)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, jv)
+		}
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value type %s", ty.FriendlyName())
+}
@@ -0,0 +1,86 @@
+package tfkschema
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var (
+	kindsOnce sync.Once
+	// kinds maps Terraform resource types to the preferred Kubernetes API GroupVersionKind
+	kinds map[string]k8sschema.GroupVersionKind
+)
+
+// ToKubernetesGroupVersionKind returns the Kubernetes API GroupVersionKind the given Terraform resource type
+// is generated from. It's the inverse of ToTerraformResourceType.
+// When several API versions map to the same resource type, the most stable version is returned,
+// e.g. kubernetes_deployment -> apps/v1 Deployment.
+// The provider's _v1 resource aliases (e.g. kubernetes_deployment_v1) are supported.
+func ToKubernetesGroupVersionKind(resourceType string) (k8sschema.GroupVersionKind, bool) {
+	kindsOnce.Do(loadKinds)
+
+	if gvk, ok := kinds[resourceType]; ok {
+		return gvk, true
+	}
+
+	gvk, ok := kinds[strings.TrimSuffix(resourceType, "_v1")]
+	return gvk, ok
+}
+
+func loadKinds() {
+	kinds = map[string]k8sschema.GroupVersionKind{}
+	priority := map[k8sschema.GroupVersion]int{}
+	for i, gv := range scheme.Scheme.PrioritizedVersionsAllGroups() {
+		priority[gv] = i
+	}
+
+	for gvk := range scheme.Scheme.AllKnownTypes() {
+		if _, ok := priority[gvk.GroupVersion()]; !ok {
+			// internal version
+			continue
+		}
+
+		obj, err := scheme.Scheme.New(gvk)
+		if err != nil {
+			continue
+		}
+
+		// only consider API objects, skipping lists, options etc.
+		ty := reflect.TypeOf(obj).Elem()
+		if _, ok := ty.FieldByName("ObjectMeta"); !ok {
+			continue
+		}
+		if _, ok := ty.FieldByName("TypeMeta"); !ok {
+			continue
+		}
+
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		resourceType := ToTerraformResourceType(obj)
+		if ResourceSchema(resourceType) == nil {
+			continue
+		}
+
+		if current, ok := kinds[resourceType]; !ok || preferGroupVersion(gvk.GroupVersion(), current.GroupVersion(), priority) {
+			kinds[resourceType] = gvk
+		}
+	}
+}
+
+// preferGroupVersion returns true if a is preferred over b.
+// More stable versions are preferred, followed by groups other than the deprecated extensions group,
+// followed by the priority order of the scheme.
+func preferGroupVersion(a, b k8sschema.GroupVersion, priority map[k8sschema.GroupVersion]int) bool {
+	if c := version.CompareKubeAwareVersionStrings(a.Version, b.Version); c != 0 {
+		return c > 0
+	}
+	if (a.Group == "extensions") != (b.Group == "extensions") {
+		return b.Group == "extensions"
+	}
+
+	return priority[a] < priority[b]
+}
@@ -0,0 +1,35 @@
+package tfkschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestToKubernetesGroupVersionKind(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		want         schema.GroupVersionKind
+		wantOk       bool
+	}{
+		{"kubernetes_deployment", schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, true},
+		{"kubernetes_deployment_v1", schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, true},
+		{"kubernetes_config_map", schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, true},
+		{"kubernetes_daemonset", schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}, true},
+		{"kubernetes_ingress", schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"}, true},
+		{"kubernetes_ingress_v1", schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}, true},
+		{"kubernetes_cron_job", schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"}, true},
+		{"kubernetes_cron_job_v1", schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"}, true},
+		{"kubernetes_cluster_role", schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, true},
+		{"kubernetes_manifest", schema.GroupVersionKind{}, false},
+		{"aws_instance", schema.GroupVersionKind{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resourceType, func(t *testing.T) {
+			got, ok := ToKubernetesGroupVersionKind(tt.resourceType)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
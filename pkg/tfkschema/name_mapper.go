@@ -200,3 +200,65 @@ func NormalizeTerraformMapKey(s string) string {
 	}
 	return s
 }
+
+// ToKubernetesField returns the field of the Kubernetes API struct type ty that the given Terraform attribute,
+// or sub-block, name maps to. It's the inverse of ToTerraformAttributeName and ToTerraformSubBlockName,
+// and path is the full schema path of the containing block, as passed to those funcs.
+// Fields of inlined structs (e.g. v1.Volume.VolumeSource) are also searched, in which case the
+// returned field Index is the index sequence for reflect.Value.FieldByIndex.
+func ToKubernetesField(ty reflect.Type, name, path string) (reflect.StructField, bool) {
+	if f, ok := findKubernetesField(ty, name, path, false); ok {
+		return f, true
+	}
+
+	return findKubernetesField(ty, name, path, true)
+}
+
+func findKubernetesField(ty reflect.Type, name, path string, toSingular bool) (reflect.StructField, bool) {
+	for ty.Kind() == reflect.Ptr {
+		ty = ty.Elem()
+	}
+	if ty.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	for i := 0; i < ty.NumField(); i++ {
+		field := ty.Field(i)
+		if field.PkgPath != "" || field.Name == "TypeMeta" {
+			// unexported
+			continue
+		}
+
+		if isInlineField(&field) {
+			if f, ok := findKubernetesField(field.Type, name, path, toSingular); ok {
+				f.Index = append([]int{i}, f.Index...)
+				return f, true
+			}
+			continue
+		}
+
+		fieldName := field.Name
+		if field.Tag.Get("protobuf") != "" {
+			fieldName = extractProtobufName(&field)
+		}
+		if NormalizeTerraformName(fieldName, toSingular, path) == name {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// ToKubernetesFieldName returns the name of the field in the Kubernetes API Object JSON / YAML representation
+func ToKubernetesFieldName(field *reflect.StructField) string {
+	return extractJsonName(field)
+}
+
+func isInlineField(field *reflect.StructField) bool {
+	for _, part := range strings.Split(field.Tag.Get("json"), ",")[1:] {
+		if part == "inline" {
+			return true
+		}
+	}
+	return false
+}
@@ -355,3 +355,42 @@ func TestNormalizeTerraformMapKey(t *testing.T) {
 		})
 	}
 }
+
+func TestToKubernetesField(t *testing.T) {
+	tests := []struct {
+		name      string
+		ty        reflect.Type
+		tfName    string
+		path      string
+		wantField string
+		wantOk    bool
+	}{
+		{"attribute", reflect.TypeOf(v1.Container{}), "image_pull_policy", "kubernetes_pod.spec.container", "ImagePullPolicy", true},
+		{"sub-block", reflect.TypeOf(v1.PodSpec{}), "container", "kubernetes_pod.spec", "Containers", true},
+		{"pointer type", reflect.TypeOf(&appsv1.Deployment{}), "spec", "kubernetes_deployment", "Spec", true},
+		{"inlined struct", reflect.TypeOf(v1.Volume{}), "config_map", "kubernetes_pod.spec.volume", "ConfigMap", true},
+		{"special case", reflect.TypeOf(appsv1.DaemonSetSpec{}), "strategy", "kubernetes_daemonset.spec", "UpdateStrategy", true},
+		{"special case", reflect.TypeOf(v1.ServiceSpec{}), "external_ips", "kubernetes_service.spec", "ExternalIPs", true},
+		{"not found", reflect.TypeOf(v1.Container{}), "foo", "kubernetes_pod.spec.container", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ToKubernetesField(tt.ty, tt.tfName, tt.path)
+			if ok != tt.wantOk {
+				t.Fatalf("ToKubernetesField() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got.Name != tt.wantField {
+				t.Errorf("ToKubernetesField() field = %v, want %v", got.Name, tt.wantField)
+			}
+			if ok {
+				ty := tt.ty
+				if ty.Kind() == reflect.Ptr {
+					ty = ty.Elem()
+				}
+				if byIndex := ty.FieldByIndex(got.Index); byIndex.Name != tt.wantField {
+					t.Errorf("ToKubernetesField() index = %v, refers to field %v", got.Index, byIndex.Name)
+				}
+			}
+		})
+	}
+}
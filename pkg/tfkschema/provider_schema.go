@@ -0,0 +1,173 @@
+package tfkschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// kubernetesProviderSuffix matches the kubernetes provider address in the schema JSON,
+// e.g. registry.terraform.io/hashicorp/kubernetes
+const kubernetesProviderSuffix = "/kubernetes"
+
+// loadedResources holds the resource schemas read by LoadProviderSchema.
+// When nil, the schema of the compiled-in provider is used.
+var loadedResources map[string]*schema.Resource
+
+// LoadProviderSchema reads the JSON document produced by `terraform providers schema -json`
+// and uses the kubernetes provider schema it contains in place of the compiled-in provider.
+// The compiled-in provider is still used for resources that are not part of the loaded schema.
+func LoadProviderSchema(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var schemas tfjson.ProviderSchemas
+	if err := json.Unmarshal(content, &schemas); err != nil {
+		return fmt.Errorf("could not parse provider schema: %w", err)
+	}
+	if err := schemas.Validate(); err != nil {
+		return fmt.Errorf("invalid provider schema: %w", err)
+	}
+
+	for addr, ps := range schemas.Schemas {
+		if !strings.HasSuffix(addr, kubernetesProviderSuffix) {
+			continue
+		}
+
+		resources := make(map[string]*schema.Resource, len(ps.ResourceSchemas))
+		for name, s := range ps.ResourceSchemas {
+			if s.Block == nil {
+				continue
+			}
+			resources[name] = convertSchemaBlock(s.Block)
+		}
+		loadedResources = resources
+
+		return nil
+	}
+
+	return fmt.Errorf("no kubernetes provider found in provider schema: %s", path)
+}
+
+// ResetProviderSchema discards the provider schema read by LoadProviderSchema, so that the schema of the
+// compiled-in provider is used again
+func ResetProviderSchema() {
+	loadedResources = nil
+}
+
+// convertSchemaBlock converts a provider schema block from its JSON representation
+// to the plugin SDK representation used by the rest of this package.
+func convertSchemaBlock(b *tfjson.SchemaBlock) *schema.Resource {
+	res := &schema.Resource{
+		Schema: map[string]*schema.Schema{},
+	}
+
+	for name, attr := range b.Attributes {
+		s := &schema.Schema{
+			Required:  attr.Required,
+			Optional:  attr.Optional,
+			Computed:  attr.Computed,
+			Sensitive: attr.Sensitive,
+		}
+
+		if nested := attr.AttributeNestedType; nested != nil {
+			s.Elem = convertSchemaBlock(&tfjson.SchemaBlock{Attributes: nested.Attributes})
+			switch nested.NestingMode {
+			case tfjson.SchemaNestingModeSingle:
+				s.Type, s.MaxItems = schema.TypeList, 1
+			case tfjson.SchemaNestingModeSet:
+				s.Type = schema.TypeSet
+			case tfjson.SchemaNestingModeMap:
+				s.Type = schema.TypeMap
+			default:
+				s.Type = schema.TypeList
+			}
+		} else {
+			s.Type, s.Elem = convertCtyType(attr.AttributeType)
+			if attr.AttributeType.IsObjectType() {
+				s.MaxItems = 1
+			}
+		}
+		if _, ok := s.Elem.(*schema.Resource); ok {
+			// objects are written as attributes, e.g. name = { ... }, rather than as blocks
+			s.ConfigMode = schema.SchemaConfigModeAttr
+		}
+
+		res.Schema[name] = s
+	}
+
+	for name, nested := range b.NestedBlocks {
+		s := &schema.Schema{
+			Type:     schema.TypeList,
+			Required: nested.MinItems > 0,
+			Optional: nested.MinItems == 0,
+			MinItems: int(nested.MinItems),
+			MaxItems: int(nested.MaxItems),
+			Elem:     &schema.Resource{Schema: map[string]*schema.Schema{}},
+		}
+		if nested.NestingMode == tfjson.SchemaNestingModeSet {
+			s.Type = schema.TypeSet
+		}
+		if nested.Block != nil {
+			s.Elem = convertSchemaBlock(nested.Block)
+		}
+
+		res.Schema[name] = s
+	}
+
+	return res
+}
+
+// convertCtyType returns the plugin SDK ValueType, and element schema for collections,
+// that is equivalent to the given cty attribute type. Numbers are not known to be integers, and are
+// converted to TypeFloat. Objects are converted to a list of a single resource, whose attributes are
+// in attribute config mode too.
+func convertCtyType(ty cty.Type) (schema.ValueType, interface{}) {
+	switch {
+	case ty == cty.String:
+		return schema.TypeString, nil
+	case ty == cty.Number:
+		return schema.TypeFloat, nil
+	case ty == cty.Bool:
+		return schema.TypeBool, nil
+	case ty.IsMapType():
+		elemTy, _ := convertCtyType(ty.ElementType())
+		return schema.TypeMap, &schema.Schema{Type: elemTy}
+	case ty.IsListType():
+		elemTy, elem := convertCtyType(ty.ElementType())
+		return schema.TypeList, elemSchema(elemTy, elem)
+	case ty.IsSetType():
+		elemTy, elem := convertCtyType(ty.ElementType())
+		return schema.TypeSet, elemSchema(elemTy, elem)
+	case ty.IsObjectType():
+		res := &schema.Resource{Schema: map[string]*schema.Schema{}}
+		for name, attrTy := range ty.AttributeTypes() {
+			t, e := convertCtyType(attrTy)
+			s := &schema.Schema{Type: t, Elem: e, Optional: true}
+			if _, ok := e.(*schema.Resource); ok {
+				s.ConfigMode = schema.SchemaConfigModeAttr
+			}
+			if attrTy.IsObjectType() {
+				s.MaxItems = 1
+			}
+			res.Schema[name] = s
+		}
+		return schema.TypeList, res
+	}
+
+	return schema.TypeString, nil
+}
+
+func elemSchema(ty schema.ValueType, elem interface{}) interface{} {
+	if res, ok := elem.(*schema.Resource); ok {
+		return res
+	}
+	return &schema.Schema{Type: ty, Elem: elem}
+}
@@ -0,0 +1,84 @@
+package tfkschema
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestLoadProviderSchema(t *testing.T) {
+	if err := LoadProviderSchema("../../test-fixtures/providerSchema.json"); err != nil {
+		t.Fatal(err)
+	}
+	defer ResetProviderSchema()
+
+	assert.True(t, IsAttributeSupported("kubernetes_config_map.data"))
+	assert.True(t, IsAttributeSupported("kubernetes_config_map.metadata.labels"))
+	assert.True(t, IsAttributeRequired("kubernetes_config_map.metadata"))
+	assert.False(t, IsAttributeRequired("kubernetes_config_map.metadata.name"))
+
+	// not present in the loaded schema, but supported by the compiled-in provider
+	assert.False(t, IsAttributeSupported("kubernetes_config_map.binary_data"))
+
+	// resources missing from the loaded schema fall back to the compiled-in provider
+	assert.True(t, IsKubernetesKindSupported(testCreateRuntimeObject(t, "apps", "v1", "Deployment")))
+	assert.True(t, IsAttributeSupported("kubernetes_deployment.spec.replicas"))
+
+	assert.True(t, IsKubernetesKindSupported(testCreateRuntimeObject(t, "core", "v1", "ConfigMap")))
+
+	data := ResourceField("kubernetes_config_map.data")
+	if assert.NotNil(t, data) {
+		assert.Equal(t, schema.TypeMap, data.Type)
+	}
+
+	metadata := ResourceField("kubernetes_config_map.metadata")
+	if assert.NotNil(t, metadata) {
+		assert.Equal(t, schema.TypeList, metadata.Type)
+		assert.Equal(t, 1, metadata.MaxItems)
+	}
+}
+
+func TestLoadProviderSchema_invalid(t *testing.T) {
+	assert.Error(t, LoadProviderSchema("../../test-fixtures/service.yaml"))
+	assert.Error(t, LoadProviderSchema("../../test-fixtures/doesNotExist.json"))
+	assert.Nil(t, loadedResources)
+}
+
+func Test_convertSchemaBlock(t *testing.T) {
+	res := convertSchemaBlock(&tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"ratio": {AttributeType: cty.Number, Optional: true},
+			"wait": {
+				AttributeType: cty.Object(map[string]cty.Type{
+					"rollout": cty.Bool,
+					"fields":  cty.Map(cty.String),
+				}),
+				Optional: true,
+			},
+			"ports": {
+				AttributeType: cty.List(cty.Object(map[string]cty.Type{"port": cty.Number})),
+				Optional:      true,
+			},
+		},
+	})
+
+	assert.Equal(t, schema.TypeFloat, res.Schema["ratio"].Type)
+
+	// object attributes stay attributes, rather than blocks
+	wait := res.Schema["wait"]
+	assert.Equal(t, schema.TypeList, wait.Type)
+	assert.Equal(t, 1, wait.MaxItems)
+	assert.Equal(t, schema.SchemaConfigModeAttr, wait.ConfigMode)
+	require.IsType(t, &schema.Resource{}, wait.Elem)
+	assert.Equal(t, schema.TypeBool, wait.Elem.(*schema.Resource).Schema["rollout"].Type)
+	assert.Equal(t, schema.TypeMap, wait.Elem.(*schema.Resource).Schema["fields"].Type)
+
+	ports := res.Schema["ports"]
+	assert.Equal(t, schema.TypeList, ports.Type)
+	assert.Equal(t, 0, ports.MaxItems)
+	assert.Equal(t, schema.SchemaConfigModeAttr, ports.ConfigMode)
+}
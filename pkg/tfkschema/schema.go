@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -13,8 +14,14 @@ import (
 var ErrAttrNotFound = fmt.Errorf("could not find attribute in resource schema")
 
 // ResourceSchema returns the named Terraform Provider Resource schema
-// as defined in the `terraform-provider-kubernetes` package
+// as defined in the `terraform-provider-kubernetes` package, or the provider schema
+// loaded with LoadProviderSchema. Resources missing from the loaded schema fall back
+// to the compiled-in provider.
 func ResourceSchema(name string) *schema.Resource {
+	if res, ok := loadedResources[name]; ok {
+		return res
+	}
+
 	prov := kubernetes.Provider()
 
 	if res, ok := prov.ResourcesMap[name]; ok {
@@ -38,6 +45,11 @@ func ResourceField(attrName string) *schema.Schema {
 
 // IsKubernetesKindSupported returns true if a matching resource is found in the Terraform provider
 func IsKubernetesKindSupported(obj runtime.Object) bool {
+	if k8sutils.IsUnstructured(obj) {
+		// unstructured objects can only be rendered as kubernetes_manifest resources
+		return false
+	}
+
 	name := ToTerraformResourceType(obj)
 
 	res := ResourceSchema(name)
@@ -75,6 +87,16 @@ func IsAttributeRequired(attrName string) bool {
 	return false
 }
 
+// AttributeDefault returns the default value of the named attribute in the Terraform provider schema.
+// Defaults are not part of provider schemas loaded with LoadProviderSchema.
+func AttributeDefault(attrName string) (interface{}, bool) {
+	if attr := ResourceField(attrName); attr != nil && attr.Default != nil {
+		return attr.Default, true
+	}
+
+	return nil, false
+}
+
 func search(m map[string]*schema.Schema, attrParts []string) *schema.Schema {
 	if len(attrParts) > 0 {
 		searchKey := attrParts[0]
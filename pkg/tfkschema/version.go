@@ -0,0 +1,70 @@
+package tfkschema
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+const (
+	// ProviderSource is the registry source address of the Terraform Kubernetes provider
+	ProviderSource = "hashicorp/kubernetes"
+
+	// providerModule is the Go module of the Terraform Kubernetes provider, whose schema is compiled in
+	providerModule = "github.com/hashicorp/terraform-provider-kubernetes"
+)
+
+// providerReleases maps commits of the provider module to the provider release they're based on.
+// The provider's v2 releases are not published with a /v2 module path, so the module is required at a
+// pseudo-version, e.g. v1.13.4-0.20230417041302-5de2ce8af29e, which doesn't tell the release.
+// Add the commit when the provider module is upgraded.
+var providerReleases = map[string]string{
+	"5de2ce8af29e": "2.19.0",
+}
+
+// ProviderVersion returns the version of the Terraform Kubernetes provider release whose schema is used,
+// e.g. 2.19.0. It returns an empty string when the version is not known, e.g. when a provider schema was
+// loaded with LoadProviderSchema.
+func ProviderVersion() string {
+	if loadedResources != nil {
+		return ""
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != providerModule {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		return providerRelease(dep.Version)
+	}
+
+	return ""
+}
+
+// ProviderVersionConstraint returns a Terraform version constraint that allows the release of the provider
+// whose schema is used, and later releases of the same major version, e.g. "~> 2.19". It returns an empty
+// string when the version is not known.
+func ProviderVersionConstraint() string {
+	segments := strings.Split(ProviderVersion(), ".")
+	if len(segments) < 2 {
+		return ""
+	}
+	return fmt.Sprintf("~> %s.%s", segments[0], segments[1])
+}
+
+// providerRelease returns the provider release of a provider module version, or an empty string if it's not
+// known
+func providerRelease(moduleVersion string) string {
+	// pseudo-versions end with the abbreviated commit hash, e.g. v1.13.4-0.20230417041302-5de2ce8af29e
+	if i := strings.LastIndex(moduleVersion, "-"); i > 0 {
+		return providerReleases[moduleVersion[i+1:]]
+	}
+
+	return strings.TrimPrefix(moduleVersion, "v")
+}
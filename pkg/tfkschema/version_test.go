@@ -0,0 +1,28 @@
+package tfkschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderVersion(t *testing.T) {
+	assert.Equal(t, "2.19.0", ProviderVersion())
+	assert.Equal(t, "~> 2.19", ProviderVersionConstraint())
+}
+
+func Test_providerRelease(t *testing.T) {
+	tests := []struct {
+		moduleVersion string
+		want          string
+	}{
+		{"v1.13.4-0.20230417041302-5de2ce8af29e", "2.19.0"},
+		{"v1.13.4-0.20230101000000-000000000000", ""},
+		{"v2.23.0", "2.23.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.moduleVersion, func(t *testing.T) {
+			assert.Equal(t, tt.want, providerRelease(tt.moduleVersion))
+		})
+	}
+}
@@ -0,0 +1,512 @@
+// Package verify checks that a conversion is lossless, by decoding the generated Terraform config
+// back to a Kubernetes API Object and comparing it with the converted object.
+package verify
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sl1pm4t/k2tf/pkg/converter"
+	"github.com/sl1pm4t/k2tf/pkg/k8sutils"
+	"github.com/sl1pm4t/k2tf/pkg/tf2k"
+	"github.com/sl1pm4t/k2tf/pkg/tfconfig"
+	"github.com/sl1pm4t/k2tf/pkg/tfkschema"
+	"github.com/zclconf/go-cty/cty"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DifferenceKind classifies a Difference
+type DifferenceKind string
+
+const (
+	// Dropped fields are set on the converted object, but missing from the generated config
+	Dropped DifferenceKind = "dropped"
+	// Added fields are missing from the converted object, but set by the generated config
+	Added DifferenceKind = "added"
+	// Changed fields are set by both, with different values
+	Changed DifferenceKind = "changed"
+	// Renamed fields are set by both with the same value, but under a different field name
+	Renamed DifferenceKind = "renamed"
+)
+
+// ignoredFields are set by the API server, and never written to the generated config
+var ignoredFields = [][]string{
+	{"status"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "generation"},
+	{"metadata", "ownerReferences"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "selfLink"},
+	{"metadata", "uid"},
+}
+
+// Difference describes a field of the converted object that did not survive the round trip
+type Difference struct {
+	Kind DifferenceKind `json:"kind"`
+	// Path of the field in the Kubernetes API Object, e.g. spec.template.spec.containers[0].image
+	Path string `json:"path"`
+	// NewPath of the field when it's Renamed
+	NewPath string `json:"newPath,omitempty"`
+	// Want is the value in the converted object
+	Want interface{} `json:"want,omitempty"`
+	// Got is the value decoded from the generated config
+	Got interface{} `json:"got,omitempty"`
+	// Unsupported is set when the difference is explained by a field the converter excluded
+	// because it's not supported by the Terraform provider schema
+	Unsupported *converter.SkippedField `json:"unsupported,omitempty"`
+}
+
+// String returns a human readable description of the difference
+func (d Difference) String() string {
+	var s string
+	switch d.Kind {
+	case Dropped:
+		s = fmt.Sprintf("dropped %s = %s", d.Path, formatValue(d.Want))
+	case Added:
+		s = fmt.Sprintf("added %s = %s", d.Path, formatValue(d.Got))
+	case Changed:
+		s = fmt.Sprintf("changed %s: %s -> %s", d.Path, formatValue(d.Want), formatValue(d.Got))
+	case Renamed:
+		s = fmt.Sprintf("renamed %s -> %s", d.Path, d.NewPath)
+	}
+
+	if d.Unsupported != nil {
+		s += fmt.Sprintf(" (not supported by Terraform schema: %s)", d.Unsupported.SchemaPath)
+	}
+	return s
+}
+
+// Batch verifies the results of a conversion. The config generated for all results is decoded together, so
+// references between resources (converter.Options.References) and to lifted values (converter.Options.Parameterize)
+// resolve to the referenced values.
+type Batch struct {
+	src []byte
+}
+
+// NewBatch returns a Batch that verifies results
+func NewBatch(results []*converter.Result) *Batch {
+	var src []byte
+	var params []*converter.Parameter
+	placeholders := tfconfig.NewBody()
+	for _, r := range results {
+		if r == nil || r.Skipped || r.Config == nil {
+			continue
+		}
+		src = append(src, r.HCL...)
+		params = append(params, r.Parameters...)
+
+		// secret values are not written to the config, decode a placeholder instead of the sensitive variable
+		for _, v := range r.SecretVariables {
+			block := tfconfig.NewBlock("variable", []string{v.Name})
+			block.Body.SetAttributeValue("default", cty.StringVal(""))
+			placeholders.AppendBlock(block)
+		}
+	}
+	src = append(src, placeholders.HCL()...)
+
+	// definitions of lifted values held by results that are not in the batch; repeated definitions are harmless
+	if len(params) > 0 {
+		body := tfconfig.NewBody()
+		converter.WriteParameters(params, body)
+		src = append(src, body.HCL()...)
+	}
+
+	return &Batch{src: src}
+}
+
+// Verify decodes the config generated for r back to a Kubernetes API Object, and returns the
+// differences with the converted object. It's a shorthand for verifying a batch holding only r.
+func Verify(r *converter.Result) ([]Difference, error) {
+	return NewBatch([]*converter.Result{r}).Verify(r)
+}
+
+// Verify decodes the config generated for r back to a Kubernetes API Object, and returns the
+// differences with the converted object. Differences caused by fields that are not supported by the
+// Terraform provider reference the matching SkippedField of r. Secret values replaced by
+// converter.Options.SecretVariables are omitted on purpose, and are not differences.
+// Resources collapsed by converter.Options.ForEach are not verified, as their config holds references
+// to each.value.
+func (b *Batch) Verify(r *converter.Result) ([]Difference, error) {
+	if r.Skipped || r.ForEachKey != "" {
+		return nil, nil
+	}
+
+	// decode to the original API version, as Terraform resources are not specific to an API version
+	decoded, err := tf2k.DecodeResourceAs(b.src, r.Address()+".tf", r.Address(), r.Object.GetObjectKind().GroupVersionKind())
+	if err != nil {
+		return nil, fmt.Errorf("could not decode generated config: %w", err)
+	}
+
+	want, err := toFields(r.Object)
+	if err != nil {
+		return nil, err
+	}
+	got, err := toFields(decoded.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeSecret(r.Object, want)
+	for _, v := range r.SecretVariables {
+		// the value is set by a sensitive variable, and omitted from the config on purpose
+		removeField(want, []string{"data", v.Key})
+		removeField(got, []string{"data", v.Key})
+	}
+	for _, path := range ignoredFields {
+		removeField(want, path)
+		removeField(got, path)
+	}
+
+	var diffs []Difference
+	compare(nil, prune(want), prune(got), &diffs)
+	diffs = findRenames(diffs)
+
+	var out []Difference
+	for _, d := range diffs {
+		if d.Kind == Dropped && isStrippedDefault(r, d.Path, d.Want) {
+			// omitted on purpose by converter.Options.StripDefaults
+			continue
+		}
+		d.Unsupported = findField(r.Object, r.SkippedFields, d.Path)
+		out = append(out, d)
+	}
+
+	return out, nil
+}
+
+func toFields(obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.DeepCopy().Object, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// normalizeSecret merges Secret stringData into data, as the API server does,
+// because the Terraform provider has no stringData attribute.
+func normalizeSecret(obj runtime.Object, fields map[string]interface{}) {
+	if k8sutils.TypeMeta(obj).Kind != "Secret" {
+		return
+	}
+
+	stringData, ok := fields["stringData"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	data, _ := fields["data"].(map[string]interface{})
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	for k, v := range stringData {
+		if s, ok := v.(string); ok {
+			data[k] = base64.StdEncoding.EncodeToString([]byte(s))
+		}
+	}
+
+	fields["data"] = data
+	delete(fields, "stringData")
+}
+
+func removeField(fields map[string]interface{}, path []string) {
+	for i, p := range path {
+		if i == len(path)-1 {
+			delete(fields, p)
+			return
+		}
+
+		next, ok := fields[p].(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields = next
+	}
+}
+
+// prune removes null values, zero values (empty strings, false and 0), and empty maps and lists, which are
+// equivalent to unset fields, and are omitted from the generated config like converter.IsZero values
+func prune(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+
+	case bool:
+		if !v {
+			return nil
+		}
+
+	case int64:
+		if v == 0 {
+			return nil
+		}
+
+	case float64:
+		if v == 0 {
+			return nil
+		}
+
+	case map[string]interface{}:
+		out := map[string]interface{}{}
+		for k, e := range v {
+			if e = prune(e); e != nil {
+				out[k] = e
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = prune(e)
+			if out[i] == nil {
+				// keep list positions stable
+				out[i] = map[string]interface{}{}
+			}
+		}
+		return out
+	}
+
+	return v
+}
+
+// compare appends the differences between want and got to diffs
+func compare(path []string, want, got interface{}, diffs *[]Difference) {
+	wantMap, wantIsMap := want.(map[string]interface{})
+	gotMap, gotIsMap := got.(map[string]interface{})
+	if wantIsMap && gotIsMap {
+		keys := map[string]bool{}
+		for k := range wantMap {
+			keys[k] = true
+		}
+		for k := range gotMap {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			compare(append(path, mapKey(k)), wantMap[k], gotMap[k], diffs)
+		}
+		return
+	}
+
+	wantList, wantIsList := want.([]interface{})
+	gotList, gotIsList := got.([]interface{})
+	if wantIsList && gotIsList {
+		for i := 0; i < len(wantList) || i < len(gotList); i++ {
+			var w, g interface{}
+			if i < len(wantList) {
+				w = wantList[i]
+			}
+			if i < len(gotList) {
+				g = gotList[i]
+			}
+			compare(append(path, "["+strconv.Itoa(i)+"]"), w, g, diffs)
+		}
+		return
+	}
+
+	p := joinPath(path)
+	switch {
+	case want == nil && got == nil:
+	case got == nil:
+		*diffs = append(*diffs, Difference{Kind: Dropped, Path: p, Want: want})
+	case want == nil:
+		*diffs = append(*diffs, Difference{Kind: Added, Path: p, Got: got})
+	case !reflect.DeepEqual(want, got):
+		*diffs = append(*diffs, Difference{Kind: Changed, Path: p, Want: want, Got: got})
+	}
+}
+
+// findRenames replaces pairs of Dropped and Added differences with the same value by a Renamed difference
+func findRenames(diffs []Difference) []Difference {
+	var out []Difference
+	used := map[int]bool{}
+
+	for i, d := range diffs {
+		if used[i] {
+			continue
+		}
+		if d.Kind == Dropped {
+			for j, a := range diffs {
+				if !used[j] && a.Kind == Added && reflect.DeepEqual(d.Want, a.Got) {
+					used[j] = true
+					d = Difference{Kind: Renamed, Path: d.Path, NewPath: a.Path, Want: d.Want, Got: a.Got}
+					break
+				}
+			}
+		}
+		out = append(out, d)
+	}
+
+	return out
+}
+
+// findField returns the field of fields, converted from obj, that explains a difference at the given path
+func findField(obj runtime.Object, fields []converter.SkippedField, path string) *converter.SkippedField {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	fieldPath := goFieldPath(obj, path)
+	for i, sf := range fields {
+		if fieldPath == sf.FieldPath || strings.HasPrefix(fieldPath, sf.FieldPath+".") {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// isStrippedDefault returns true if every field of the value v at path was omitted by converter.Options.StripDefaults
+func isStrippedDefault(r *converter.Result, path string, v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if !isStrippedDefault(r, joinPath([]string{path, mapKey(k)}), e) {
+				return false
+			}
+		}
+		return true
+
+	case []interface{}:
+		for i, e := range v {
+			if !isStrippedDefault(r, path+"["+strconv.Itoa(i)+"]", e) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return findField(r.Object, r.DefaultFields, path) != nil
+}
+
+// goFieldPath converts a field path in the JSON representation of obj to the path of Go struct field names
+// used by converter.SkippedField, e.g. spec.template.spec.containers[0].image -> Deployment.Spec.Template.Spec.Containers.Image
+func goFieldPath(obj runtime.Object, path string) string {
+	parts := []string{k8sutils.TypeMeta(obj).Kind}
+
+	ty := reflect.TypeOf(obj)
+	for _, seg := range splitPath(path) {
+		for ty.Kind() == reflect.Ptr || ty.Kind() == reflect.Slice {
+			ty = ty.Elem()
+		}
+		if strings.HasPrefix(seg, "[") {
+			continue
+		}
+		if ty.Kind() != reflect.Struct {
+			// map keys are not part of the field path
+			break
+		}
+
+		field, ok := jsonField(ty, seg)
+		if !ok {
+			break
+		}
+		parts = append(parts, field.Name)
+		ty = field.Type
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// jsonField returns the field of the struct type ty with the given JSON name, searching inlined structs too
+func jsonField(ty reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < ty.NumField(); i++ {
+		f := ty.Field(i)
+		tag := f.Tag.Get("json")
+		if strings.Contains(tag, ",inline") {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if inner, ok := jsonField(ft, name); ok {
+					return inner, true
+				}
+			}
+			continue
+		}
+		if tag != "" && tfkschema.ToKubernetesFieldName(&f) == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func mapKey(k string) string {
+	if strings.ContainsAny(k, "./[]") {
+		return "[" + strconv.Quote(k) + "]"
+	}
+	return k
+}
+
+func joinPath(path []string) string {
+	var sb strings.Builder
+	for _, p := range path {
+		if sb.Len() > 0 && !strings.HasPrefix(p, "[") {
+			sb.WriteString(".")
+		}
+		sb.WriteString(p)
+	}
+	return sb.String()
+}
+
+// splitPath splits a path created by joinPath in to segments
+func splitPath(path string) []string {
+	var segs []string
+	for len(path) > 0 {
+		switch {
+		case strings.HasPrefix(path, "[\""):
+			end := strings.Index(path, "\"]")
+			if end == -1 {
+				return append(segs, path)
+			}
+			k, _ := strconv.Unquote(path[1 : end+1])
+			segs = append(segs, k)
+			path = path[end+2:]
+		case strings.HasPrefix(path, "["):
+			end := strings.Index(path, "]")
+			segs = append(segs, path[:end+1])
+			path = path[end+1:]
+		case strings.HasPrefix(path, "."):
+			path = path[1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end == -1 {
+				end = len(path)
+			}
+			segs = append(segs, path[:end])
+			path = path[end:]
+		}
+	}
+	return segs
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case map[string]interface{}, []interface{}:
+		return "{...}"
+	}
+	return fmt.Sprint(v)
+}
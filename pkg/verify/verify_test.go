@@ -0,0 +1,210 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sl1pm4t/k2tf/pkg/converter"
+	"github.com/sl1pm4t/k2tf/pkg/k8sparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		opts      converter.Options
+		wantDiffs []string
+	}{
+		{
+			"lossless",
+			"service.yaml",
+			converter.Options{},
+			nil,
+		},
+		{
+			"secret stringData",
+			"secretStringData.yaml",
+			converter.Options{},
+			nil,
+		},
+		{
+			"older API version",
+			"ingress.yaml",
+			converter.Options{},
+			nil,
+		},
+		{
+			"zero values",
+			"deployment.yaml",
+			converter.Options{},
+			nil,
+		},
+		{
+			"unsupported fields",
+			"podNodeExporter.yaml",
+			converter.Options{},
+			[]string{
+				`dropped spec.serviceAccount = "default" (not supported by Terraform schema: kubernetes_pod.spec.service_account)`,
+			},
+		},
+		{
+			"unsupported block",
+			"namespace_w_spec.yaml",
+			converter.Options{},
+			[]string{
+				"dropped spec = {...} (not supported by Terraform schema: kubernetes_namespace.spec)",
+			},
+		},
+		{
+			"include unsupported",
+			"namespace_w_spec.yaml",
+			converter.Options{IncludeUnsupported: true},
+			nil,
+		},
+		{
+			"strip defaults",
+			"defaults/deployment.yaml",
+			converter.Options{StripDefaults: true},
+			nil,
+		},
+		{
+			"manifest",
+			"manifest/cronTab.yaml",
+			converter.Options{ManifestUnsupported: true},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("../../test-fixtures", tt.input))
+			require.NoError(t, err)
+			defer f.Close()
+
+			objs, err := k8sparser.ParseYAML(f)
+			require.NoError(t, err)
+
+			r, err := converter.New(tt.opts).ConvertObject(objs[0])
+			require.NoError(t, err)
+
+			diffs, err := Verify(r)
+			require.NoError(t, err)
+
+			var got []string
+			for _, d := range diffs {
+				got = append(got, d.String())
+			}
+			assert.Equal(t, tt.wantDiffs, got)
+		})
+	}
+}
+
+func TestVerify_parameters(t *testing.T) {
+	f, err := os.Open(filepath.Join("../../test-fixtures", "parameters", "shop.yaml"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	objs, err := k8sparser.ParseYAML(f)
+	require.NoError(t, err)
+
+	results, err := converter.New(converter.Options{Parameterize: true}).Convert(objs)
+	require.NoError(t, err)
+
+	for _, r := range results {
+		// resources that reference local values defined with another resource are verified too
+		diffs, err := Verify(r)
+		require.NoError(t, err)
+		assert.Empty(t, diffs, r.Address())
+	}
+}
+
+func TestBatch_Verify(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  converter.Options
+	}{
+		{
+			// references to other resources, and to the namespace in metadata, resolve to the referenced names
+			"references",
+			"references/app.yaml",
+			converter.Options{References: true},
+		},
+		{
+			// secret values held by sensitive variables are omitted on purpose
+			"secret variables",
+			"references/app.yaml",
+			converter.Options{SecretVariables: true},
+		},
+		{
+			"references and secret variables",
+			"references/app.yaml",
+			converter.Options{References: true, SecretVariables: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("../../test-fixtures", tt.input))
+			require.NoError(t, err)
+			defer f.Close()
+
+			objs, err := k8sparser.ParseYAML(f)
+			require.NoError(t, err)
+
+			results, err := converter.New(tt.opts).Convert(objs)
+			require.NoError(t, err)
+
+			batch := NewBatch(results)
+			for _, r := range results {
+				diffs, err := batch.Verify(r)
+				require.NoError(t, err)
+				assert.Empty(t, diffs, r.Address())
+			}
+		})
+	}
+}
+
+func Test_prune(t *testing.T) {
+	got := prune(map[string]interface{}{
+		"name":     "web",
+		"optional": false,
+		"enabled":  true,
+		"mode":     int64(0),
+		"replicas": int64(2),
+		"ratio":    float64(0),
+		"empty":    "",
+		"items":    []interface{}{map[string]interface{}{"optional": false}},
+		"null":     nil,
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"name":     "web",
+		"enabled":  true,
+		"replicas": int64(2),
+		// list positions are kept
+		"items": []interface{}{map[string]interface{}{}},
+	}, got)
+}
+
+func Test_findRenames(t *testing.T) {
+	diffs := []Difference{
+		{Kind: Dropped, Path: "spec.foo", Want: "a"},
+		{Kind: Dropped, Path: "spec.bar", Want: "b"},
+		{Kind: Added, Path: "spec.baz", Got: "a"},
+	}
+
+	assert.Equal(t, []Difference{
+		{Kind: Renamed, Path: "spec.foo", NewPath: "spec.baz", Want: "a", Got: "a"},
+		{Kind: Dropped, Path: "spec.bar", Want: "b"},
+	}, findRenames(diffs))
+}
+
+func Test_splitPath(t *testing.T) {
+	path := joinPath([]string{"metadata", "annotations", mapKey("prometheus.io/port")})
+	assert.Equal(t, `metadata.annotations["prometheus.io/port"]`, path)
+	assert.Equal(t, []string{"metadata", "annotations", "prometheus.io/port"}, splitPath(path))
+
+	assert.Equal(t, []string{"spec", "containers", "[0]", "image"}, splitPath("spec.containers[0].image"))
+}
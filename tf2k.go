@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sl1pm4t/k2tf/pkg/file_io"
+	"github.com/sl1pm4t/k2tf/pkg/tf2k"
+	flag "github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// tf2kMain runs the tf2k command, which converts Terraform config back to Kubernetes YAML, and returns the exit
+// code. The resources that could be decoded are written even when others could not, and exitError is returned.
+func tf2kMain(args []string) int {
+	fs := flag.NewFlagSet("tf2k", flag.ContinueOnError)
+	fs.BoolVarP(&overwriteExisting, "overwrite-existing", "x", false, "allow overwriting existing output file")
+	fs.BoolVarP(&debug, "debug", "d", false, "enable debug output")
+	fs.StringVarP(&input, "filepath", "f", "-", `file or directory that contains the Terraform config to convert. Use "-" to read from stdin`)
+	fs.StringVarP(&output, "output", "o", "-", `file where Kubernetes YAML will be written`)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	setupLogOutput()
+
+	var results []*tf2k.Result
+	var err error
+	if input == "-" || input == "" {
+		src, rerr := io.ReadAll(os.Stdin)
+		if rerr != nil {
+			log.Fatal().Err(rerr).Msg("could not read stdin")
+		}
+		results, err = tf2k.Decode(src, "<stdin>")
+
+	} else {
+		results, err = tf2k.DecodeFiles(terraformFiles(input))
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("error decoding Terraform config")
+	}
+	if results == nil && err != nil {
+		return exitError
+	}
+
+	objs := make([]runtime.Object, 0, len(results))
+	for _, r := range results {
+		objs = append(objs, r.Object)
+	}
+	log.Debug().Msgf("decoded %d objects from input", len(objs))
+
+	w, closer := file_io.SetupOutput(output, overwriteExisting)
+	defer closer()
+
+	if err := tf2k.WriteYAML(w, objs); err != nil {
+		log.Fatal().Err(err).Msg("could not write YAML")
+	}
+
+	if err != nil {
+		return exitError
+	}
+	return exitOK
+}
+
+// terraformFiles returns the Terraform config files of the input file or directory
+func terraformFiles(input string) []string {
+	fi, err := os.Stat(input)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", input).Msg("could not read input")
+	}
+	if !fi.IsDir() {
+		return []string{input}
+	}
+
+	entries, err := os.ReadDir(input)
+	if err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".tf") {
+			files = append(files, filepath.Join(input, e.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	return files
+}